@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -17,6 +18,9 @@ var addEntryCmd = &cobra.Command{
 	Long: `Add a key/value entry to a secret that stores two parallel
 semicolon-separated lists in two data keys, matched by index position.
 
+For tables of three or more parallel lists, repeat --entries-val for every
+value column and give one --value per column, in the same order.
+
 Append to the end (default):
   k8s-secret-manifest add-entry \
     --input secret.yaml \
@@ -32,7 +36,18 @@ Insert at a specific position (--index 1 inserts between existing index 0 and 1)
     --entries-val  BACKEND_PASSWORDS \
     --key carol \
     --value newpass \
-    --index 1`,
+    --index 1
+
+Add a row to a users/passwords/databases table:
+  k8s-secret-manifest add-entry \
+    --input secret.yaml \
+    --entries-key  DB_USERS \
+    --entries-val  DB_PASSWORDS --entries-val DB_NAMES \
+    --key carol \
+    --value newpass --value reporting
+
+--stamp records the change of the value lists in rotation annotations (see
+stale), and --max-age also sets its maximum age.`,
 	RunE: runAddEntry,
 }
 
@@ -42,35 +57,54 @@ func init() {
 
 	addEntryCmd.Flags().StringP("output", "o", "",
 		"Output file path (default: same as --input)")
+	addEntryCmd.Flags().Bool("backup", false,
+		"Keep the previous version of the output file as <output>.bak")
+	addSelectFlag(addEntryCmd)
 
 	addEntryCmd.Flags().StringP("entries-key", "K", "",
 		"Data key name holding the semicolon-separated identifier list (required)")
 	_ = addEntryCmd.MarkFlagRequired("entries-key")
 
-	addEntryCmd.Flags().StringP("entries-val", "V", "",
-		"Data key name holding the semicolon-separated value list (required)")
+	addEntryCmd.Flags().StringArrayP("entries-val", "V", nil,
+		"Data key name holding a semicolon-separated value list; repeat for each value column (required)")
 	_ = addEntryCmd.MarkFlagRequired("entries-val")
 
 	addEntryCmd.Flags().StringP("key", "k", "", "Identifier for the new entry (required)")
 	_ = addEntryCmd.MarkFlagRequired("key")
 
-	addEntryCmd.Flags().StringP("value", "v", "", "Value for the new entry (required)")
+	addEntryCmd.Flags().StringArrayP("value", "v", nil,
+		"Value for the new entry; repeat once per --entries-val, in the same order (required)")
 	_ = addEntryCmd.MarkFlagRequired("value")
 
 	addEntryCmd.Flags().IntP("index", "x", -1,
 		"Insert position (0 = first, default: append to end)")
 	addEntryCmd.Flags().StringP("separator", "S", ";", "Separator used in the list values")
+	addQuoteFlag(addEntryCmd)
+	addStampFlags(addEntryCmd)
 }
 
 func runAddEntry(cmd *cobra.Command, _ []string) error {
 	inputPath, _ := cmd.Flags().GetString("input")
 	outputPath, _ := cmd.Flags().GetString("output")
+	backup, _ := cmd.Flags().GetBool("backup")
 	entriesKey, _ := cmd.Flags().GetString("entries-key")
-	entriesVal, _ := cmd.Flags().GetString("entries-val")
+	entriesVals, _ := cmd.Flags().GetStringArray("entries-val")
 	key, _ := cmd.Flags().GetString("key")
-	value, _ := cmd.Flags().GetString("value")
+	values := arrayFlag(cmd, "value")
 	idx, _ := cmd.Flags().GetInt("index")
-	sep, _ := cmd.Flags().GetString("separator")
+
+	st, err := stampFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	codec, err := entryCodec(cmd)
+	if err != nil {
+		return err
+	}
+	if len(values) != len(entriesVals) {
+		return fmt.Errorf("--value given %d time(s) for %d --entries-val column(s)", len(values), len(entriesVals))
+	}
+	row := append([]string{key}, values...)
 
 	if outputPath == "" {
 		outputPath = inputPath
@@ -82,26 +116,43 @@ func runAddEntry(cmd *cobra.Command, _ []string) error {
 	}
 
 	return withExclusiveLock(outputPath, func() error {
-		s, err := manifest.FromFile(safeInput)
+		s, err := loadSecretToChange(cmd, safeInput)
 		if err != nil {
 			return fmt.Errorf("load secret: %w", err)
 		}
 
-		entries, err := loadEntries(s, entriesKey, entriesVal, sep)
+		table, err := loadTable(s, entriesKey, entriesVals, codec)
 		if err != nil {
 			return err
 		}
+		if err := codec.CheckRow(table, row); err != nil {
+			return quoteHint(err)
+		}
 
 		if idx >= 0 {
-			entries, err = entrylist.Insert(entries, idx, key, value)
+			table, err = table.Insert(idx, row)
 		} else {
-			entries, err = entrylist.Add(entries, key, value)
+			table, err = table.Add(row)
 		}
 		if err != nil {
 			return err
 		}
 
-		storeEntries(s, entriesKey, entriesVal, sep, entries)
+		if err := storeTable(s, codec, table); err != nil {
+			return err
+		}
+		if err := st.stamp(s, entriesVals...); err != nil {
+			return err
+		}
+
+		if err := checkLimits(s); err != nil {
+			return err
+		}
+		if backup {
+			if err := backupExisting(outputPath); err != nil {
+				return err
+			}
+		}
 
 		if err := writeSecretTo(outputPath, s); err != nil {
 			return err
@@ -112,31 +163,112 @@ func runAddEntry(cmd *cobra.Command, _ []string) error {
 	})
 }
 
-// loadEntries decodes the two list keys from the secret and parses them.
-// A missing key is treated as an empty list so the first entry can be added freely.
-func loadEntries(s *corev1.Secret, entriesKey, entriesVal, sep string) ([]entrylist.Entry, error) {
-	keysPlain := string(s.Data[entriesKey])
-	valsPlain := string(s.Data[entriesVal])
+// loadTable decodes the identifier list and value lists from the secret into
+// a table. A missing key is treated as an empty list so the first entry can
+// be added freely.
+func loadTable(s *corev1.Secret, entriesKey string, entriesVals []string, codec entrylist.Codec) (entrylist.Table, error) {
+	columns := append([]string{entriesKey}, entriesVals...)
+	lists := make([]string, len(columns))
+	for i, col := range columns {
+		lists[i] = string(s.Data[col])
+	}
+	return codec.ParseTable(columns, lists)
+}
+
+// storeTable serialises a table and writes every column back into the secret.
+func storeTable(s *corev1.Secret, codec entrylist.Codec, table entrylist.Table) error {
+	lists, err := codec.SerializeTable(table)
+	if err != nil {
+		return quoteHint(err)
+	}
+	for i, col := range table.Columns {
+		manifest.SetPlainValue(s, col, lists[i])
+	}
+	return nil
+}
 
-	if keysPlain == "" && valsPlain == "" {
-		return []entrylist.Entry{}, nil
+// arrayFlag returns the values of a repeatable string flag. Unlike
+// GetStringArray it keeps a single empty value, e.g. --value "".
+func arrayFlag(cmd *cobra.Command, name string) []string {
+	if v, ok := cmd.Flags().Lookup(name).Value.(interface{ GetSlice() []string }); ok {
+		return v.GetSlice()
 	}
+	return nil
+}
+
+// addQuoteFlag registers --quote on a paired-list command.
+func addQuoteFlag(cmd *cobra.Command) {
+	cmd.Flags().String("quote", "",
+		"Quote character for list items that contain the separator, have surrounding whitespace, or are empty (e.g. '\"'); "+
+			"read and write the lists with the same --quote")
+}
 
-	return entrylist.Parse(keysPlain, valsPlain, sep)
+// entryCodec returns the paired-list codec selected by --separator and --quote.
+func entryCodec(cmd *cobra.Command) (entrylist.Codec, error) {
+	sep, _ := cmd.Flags().GetString("separator")
+	quote, _ := cmd.Flags().GetString("quote")
+	codec := entrylist.Codec{Sep: sep, Quote: quote}
+	if err := codec.Validate(); err != nil {
+		return entrylist.Codec{}, fmt.Errorf("--separator/--quote: %w", err)
+	}
+	return codec, nil
 }
 
-// storeEntries serialises entries and writes them back into the secret.
-func storeEntries(s *corev1.Secret, entriesKey, entriesVal, sep string, entries []entrylist.Entry) {
-	keysVal, valsVal := entrylist.Serialize(entries, sep)
-	manifest.SetPlainValue(s, entriesKey, keysVal)
-	manifest.SetPlainValue(s, entriesVal, valsVal)
+// quoteHint points at --quote when err is about an item that needs quoting.
+func quoteHint(err error) error {
+	if errors.Is(err, entrylist.ErrNeedsQuoting) {
+		return fmt.Errorf("%w (pass --quote '\"' to store it quoted)", err)
+	}
+	return err
 }
 
 // writeSecretTo serialises a secret and writes it to a file or stdout.
 func writeSecretTo(path string, s *corev1.Secret) error {
-	data, err := manifest.ToYAML(s)
+	return writeSecretAs(path, s, manifest.EmitData)
+}
+
+// writeSecretAs is writeSecretTo with an explicit emit mode (see --emit).
+// Secrets that violate API server limits are rejected before anything is
+// written. When path already holds several documents, only the Secret with
+// the same name and namespace is replaced, or the Secret is appended.
+func writeSecretAs(path string, s *corev1.Secret, emit string) error {
+	if err := checkLimits(s); err != nil {
+		return err
+	}
+	data, err := manifest.ToYAMLAs(s, emit)
 	if err != nil {
 		return err
 	}
+	if data, err = spliceIntoStream(path, s, data); err != nil {
+		return err
+	}
 	return writeOutput(path, data)
 }
+
+// spliceIntoStream returns body spliced into the multi-document stream at
+// path (see manifest.SpliceSecret), or body itself when path is stdout, does
+// not exist, or holds a single document.
+func spliceIntoStream(path string, s *corev1.Secret, body []byte) ([]byte, error) {
+	if path == "" {
+		return body, nil
+	}
+	safe, err := safePath("--output", path)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := os.ReadFile(safe)
+	if os.IsNotExist(err) {
+		return body, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %q: %w", safe, err)
+	}
+	if len(manifest.SplitDocuments(existing)) <= 1 {
+		return body, nil
+	}
+	out, err := manifest.SpliceSecret(existing, s, body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
@@ -5,7 +5,9 @@ import (
 	"os"
 	"sort"
 
-	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -19,22 +21,35 @@ Keys present only in the second file are shown with +.
 Keys present in both with different values are shown with - and +.
 Unchanged keys are hidden by default (use --unchanged to show them).
 
+Values are masked by default as their length and a short SHA-256
+fingerprint, so a change is still visible without printing the secret.
+Pass --reveal (or --reveal-key KEY) to print values in clear text.
+
+--from and --to may each be a file, multi-document stream, directory, or
+glob. When both resolve to a single Secret they are compared directly;
+otherwise Secrets are paired by namespace/name and Secrets present on only
+one side are reported as added or removed.
+
 Color output is enabled by default; set NO_COLOR=1 to disable.
 
 Example:
   k8s-secret-manifest diff --from secret-v1.yaml --to secret-v2.yaml
-  k8s-secret-manifest diff --from secret-v1.yaml --to secret-v2.yaml --unchanged`,
+  k8s-secret-manifest diff --from secret-v1.yaml --to secret-v2.yaml --unchanged
+  k8s-secret-manifest diff --from secret-v1.yaml --to secret-v2.yaml --reveal-key API_KEY
+  k8s-secret-manifest diff --from ./base/ --to ./overlays/prod/`,
 	RunE: runDiff,
 }
 
 func init() {
-	diffCmd.Flags().StringP("from", "A", "", "Base secret file (required)")
+	diffCmd.Flags().StringP("from", "A", "", "Base secret file, directory, or glob (required)")
 	_ = diffCmd.MarkFlagRequired("from")
 
-	diffCmd.Flags().StringP("to", "B", "", "New secret file (required)")
+	diffCmd.Flags().StringP("to", "B", "", "New secret file, directory, or glob (required)")
 	_ = diffCmd.MarkFlagRequired("to")
 
 	diffCmd.Flags().Bool("unchanged", false, "Also show unchanged keys")
+	addRevealFlags(diffCmd)
+	addAgeIdentityFlag(diffCmd)
 }
 
 func runDiff(cmd *cobra.Command, _ []string) error {
@@ -42,59 +57,150 @@ func runDiff(cmd *cobra.Command, _ []string) error {
 	toPath, _ := cmd.Flags().GetString("to")
 	showUnchanged, _ := cmd.Flags().GetBool("unchanged")
 
-	safeFrom, err := safePath("--from", fromPath)
-	if err != nil {
-		return err
-	}
-	safeTo, err := safePath("--to", toPath)
+	format, err := outputFormat(cmd)
 	if err != nil {
 		return err
 	}
 
-	a, err := manifest.FromFile(safeFrom)
+	fromSecrets, err := loadSecrets(cmd, "--from", fromPath, "")
 	if err != nil {
 		return fmt.Errorf("load --from: %w", err)
 	}
-	b, err := manifest.FromFile(safeTo)
+	toSecrets, err := loadSecrets(cmd, "--to", toPath, "")
 	if err != nil {
 		return fmt.Errorf("load --to: %w", err)
 	}
 
-	color := os.Getenv("NO_COLOR") == ""
+	r := newRedactor(cmd)
+	var pairs []pairDiff
+
+	// Two single Secrets are compared directly, even if their names differ.
+	if len(fromSecrets) == 1 && len(toSecrets) == 1 {
+		pairs = append(pairs, comparePair(fromSecrets[0], toSecrets[0], r, showUnchanged))
+	} else {
+		// Otherwise pair Secrets by namespace/name.
+		fromByID, err := indexSecrets("--from", fromSecrets)
+		if err != nil {
+			return err
+		}
+		toByID, err := indexSecrets("--to", toSecrets)
+		if err != nil {
+			return err
+		}
+
+		idSet := make(map[string]struct{})
+		for id := range fromByID {
+			idSet[id] = struct{}{}
+		}
+		for id := range toByID {
+			idSet[id] = struct{}{}
+		}
+		ids := make([]string, 0, len(idSet))
+		for id := range idSet {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
 
-	red := func(s string) string {
-		if color {
-			return "\033[31m" + s + "\033[0m"
+		for _, id := range ids {
+			a, inA := fromByID[id]
+			b, inB := toByID[id]
+			switch {
+			case inA && !inB:
+				pairs = append(pairs, pairDiff{diff: onlyOneSide(a, report.KindRemoved)})
+			case !inA && inB:
+				pairs = append(pairs, pairDiff{diff: onlyOneSide(b, report.KindAdded)})
+			default:
+				pairs = append(pairs, comparePair(a, b, r, showUnchanged))
+			}
 		}
-		return s
 	}
-	green := func(s string) string {
-		if color {
-			return "\033[32m" + s + "\033[0m"
+
+	if format != formatText {
+		doc := report.Diff{Secrets: make([]report.SecretDiff, 0, len(pairs))}
+		for _, pd := range pairs {
+			doc.Secrets = append(doc.Secrets, pd.diff)
 		}
-		return s
+		return printStructured(format, doc)
 	}
-	yellow := func(s string) string {
-		if color {
-			return "\033[33m" + s + "\033[0m"
+
+	p := newDiffPrinter()
+	for i, pd := range pairs {
+		if i > 0 {
+			fmt.Println()
 		}
-		return s
+		p.print(pd, r)
 	}
+	return nil
+}
 
-	// Header
-	fmt.Printf("--- %s (%s/%s  type: %s)\n", safeFrom, a.Namespace, a.Name, a.Type)
-	fmt.Printf("+++ %s (%s/%s  type: %s)\n", safeTo, b.Namespace, b.Name, b.Type)
+// pairDiff is the comparison of one pair of Secrets. from and to are nil when
+// the Secret exists on only one side.
+type pairDiff struct {
+	from, to *corev1.Secret
+	diff     report.SecretDiff
+}
 
-	// Metadata differences
-	if a.Name != b.Name {
-		fmt.Println(red(fmt.Sprintf("~ name: %s → %s", a.Name, b.Name)))
+func comparePair(from, to sourcedSecret, r *redactor, showUnchanged bool) pairDiff {
+	return pairDiff{
+		from: from.Secret,
+		to:   to.Secret,
+		diff: compareSecrets(from, to, r, showUnchanged),
 	}
-	if a.Namespace != b.Namespace {
-		fmt.Println(yellow(fmt.Sprintf("~ namespace: %s → %s", a.Namespace, b.Namespace)))
+}
+
+// indexSecrets maps Secrets by "namespace/name", rejecting duplicates.
+func indexSecrets(flag string, list []sourcedSecret) (map[string]sourcedSecret, error) {
+	m := make(map[string]sourcedSecret, len(list))
+	for _, src := range list {
+		id := src.Secret.Namespace + "/" + src.Secret.Name
+		if prev, dup := m[id]; dup {
+			return nil, fmt.Errorf("%s: Secret %s appears in both %s and %s", flag, id, prev.Path, src.Path)
+		}
+		m[id] = src
 	}
-	if a.Type != b.Type {
-		fmt.Println(yellow(fmt.Sprintf("~ type: %s → %s", a.Type, b.Type)))
+	return m, nil
+}
+
+// onlyOneSide describes a Secret present on only one side of the diff.
+func onlyOneSide(src sourcedSecret, kind string) report.SecretDiff {
+	d := report.SecretDiff{
+		Namespace: src.Secret.Namespace,
+		Name:      src.Secret.Name,
+		Kind:      kind,
+		Metadata:  []report.FieldChange{},
+		Changes:   []report.KeyChange{},
 	}
+	if kind == report.KindRemoved {
+		d.FromFile = src.Path
+	} else {
+		d.ToFile = src.Path
+	}
+	return d
+}
+
+// compareSecrets computes the metadata and data differences between two
+// Secrets. Values are masked by r; unchanged keys are included only when
+// showUnchanged is set.
+func compareSecrets(from, to sourcedSecret, r *redactor, showUnchanged bool) report.SecretDiff {
+	a, b := from.Secret, to.Secret
+	d := report.SecretDiff{
+		Namespace: b.Namespace,
+		Name:      b.Name,
+		Kind:      report.KindUnchanged,
+		FromFile:  from.Path,
+		ToFile:    to.Path,
+		Metadata:  []report.FieldChange{},
+		Changes:   []report.KeyChange{},
+	}
+
+	field := func(name, x, y string) {
+		if x != y {
+			d.Metadata = append(d.Metadata, report.FieldChange{Field: name, From: x, To: y})
+		}
+	}
+	field("name", a.Name, b.Name)
+	field("namespace", a.Namespace, b.Namespace)
+	field("type", string(a.Type), string(b.Type))
 
 	// Collect all keys
 	keySet := make(map[string]struct{})
@@ -110,34 +216,112 @@ func runDiff(cmd *cobra.Command, _ []string) error {
 	}
 	sort.Strings(keys)
 
-	// Diff data
-	changed := 0
 	for _, k := range keys {
-		_, inA := a.Data[k]
-		_, inB := b.Data[k]
-		aVal := string(a.Data[k])
-		bVal := string(b.Data[k])
+		aVal, inA := a.Data[k]
+		bVal, inB := b.Data[k]
+		c := report.KeyChange{Key: k}
+		if inA {
+			v := r.reportValue(k, aVal)
+			c.From = &v
+		}
+		if inB {
+			v := r.reportValue(k, bVal)
+			c.To = &v
+		}
 
 		switch {
 		case inA && !inB:
-			fmt.Println(red(fmt.Sprintf("- %s=%s", k, aVal)))
-			changed++
+			c.Kind = report.KindRemoved
 		case !inA && inB:
-			fmt.Println(green(fmt.Sprintf("+ %s=%s", k, bVal)))
-			changed++
-		case aVal != bVal:
-			fmt.Println(red(fmt.Sprintf("- %s=%s", k, aVal)))
-			fmt.Println(green(fmt.Sprintf("+ %s=%s", k, bVal)))
-			changed++
+			c.Kind = report.KindAdded
+		case string(aVal) != string(bVal):
+			c.Kind = report.KindChanged
 		default:
-			if showUnchanged {
-				fmt.Printf("  %s=%s\n", k, aVal)
+			if !showUnchanged {
+				continue
 			}
+			c.Kind = report.KindUnchanged
+		}
+		d.Changes = append(d.Changes, c)
+	}
+
+	for _, c := range d.Changes {
+		if c.Kind != report.KindUnchanged {
+			d.Kind = report.KindChanged
+			break
+		}
+	}
+	if len(d.Metadata) > 0 {
+		d.Kind = report.KindChanged
+	}
+	return d
+}
+
+// diffPrinter renders decoded Secret diffs as text, optionally colorised.
+type diffPrinter struct {
+	color bool
+}
+
+func newDiffPrinter() *diffPrinter {
+	return &diffPrinter{color: os.Getenv("NO_COLOR") == ""}
+}
+
+func (p *diffPrinter) paint(code, s string) string {
+	if p.color {
+		return code + s + "\033[0m"
+	}
+	return s
+}
+
+func (p *diffPrinter) red(s string) string    { return p.paint("\033[31m", s) }
+func (p *diffPrinter) green(s string) string  { return p.paint("\033[32m", s) }
+func (p *diffPrinter) yellow(s string) string { return p.paint("\033[33m", s) }
+
+// print writes one Secret diff: the header, metadata differences, and data
+// key changes, with values rendered by r.
+func (p *diffPrinter) print(pd pairDiff, r *redactor) {
+	d := pd.diff
+	id := d.Namespace + "/" + d.Name
+	switch d.Kind {
+	case report.KindRemoved:
+		fmt.Println(p.red(fmt.Sprintf("- Secret %s (only in %s)", id, d.FromFile)))
+		return
+	case report.KindAdded:
+		fmt.Println(p.green(fmt.Sprintf("+ Secret %s (only in %s)", id, d.ToFile)))
+		return
+	}
+
+	// Header
+	a, b := pd.from, pd.to
+	fmt.Printf("--- %s (%s/%s  type: %s)\n", d.FromFile, a.Namespace, a.Name, a.Type)
+	fmt.Printf("+++ %s (%s/%s  type: %s)\n", d.ToFile, b.Namespace, b.Name, b.Type)
+
+	// Metadata differences
+	for _, m := range d.Metadata {
+		line := fmt.Sprintf("~ %s: %s → %s", m.Field, m.From, m.To)
+		if m.Field == "name" {
+			fmt.Println(p.red(line))
+		} else {
+			fmt.Println(p.yellow(line))
 		}
 	}
 
-	if changed == 0 {
+	// Data differences
+	for _, c := range d.Changes {
+		switch c.Kind {
+		case report.KindRemoved:
+			fmt.Println(p.red(fmt.Sprintf("- %s=%s", c.Key, r.value(c.Key, a.Data[c.Key]))))
+		case report.KindAdded:
+			fmt.Println(p.green(fmt.Sprintf("+ %s=%s", c.Key, r.value(c.Key, b.Data[c.Key]))))
+		case report.KindChanged:
+			fmt.Println(p.red(fmt.Sprintf("- %s=%s", c.Key, r.value(c.Key, a.Data[c.Key]))))
+			fmt.Println(p.green(fmt.Sprintf("+ %s=%s", c.Key, r.value(c.Key, b.Data[c.Key]))))
+		default:
+			fmt.Printf("  %s=%s\n", c.Key, r.value(c.Key, a.Data[c.Key]))
+		}
+	}
+
+	if d.Kind == report.KindUnchanged {
 		fmt.Println("(no differences)")
 	}
-	return nil
 }
@@ -1,13 +1,23 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
-	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	yaml "go.yaml.in/yaml/v2"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/report"
+	"github.com/pbsladek/k8s-secret-manifest/internal/rotation"
 	"github.com/pbsladek/k8s-secret-manifest/internal/validate"
 	"github.com/spf13/cobra"
 )
@@ -15,14 +25,27 @@ import (
 var editCmd = &cobra.Command{
 	Use:   "edit",
 	Short: "Edit a Secret manifest's values interactively",
-	Long: `Open the decoded Secret values in $EDITOR as a .env-style file.
+	Long: `Open the decoded Secret in $EDITOR as a YAML document with its type,
+labels, annotations, and data values.
 
 On save and exit, the updated values are re-encoded and the Secret manifest
-is written back. The EDITOR environment variable is used; falls back to "vi".
+is written back, and the changed keys are listed with their values masked.
+The EDITOR environment variable is used; falls back to "vi".
+
+If the edited document is invalid, the editor reopens with the error in a
+comment at the top and your changes kept; save it unchanged to give up.
+Nothing is written when nothing changed.
+
+If the file changed while the editor was open (e.g. a concurrent update or
+rotate), the two sets of changes are merged key by key on save. Keys changed
+differently on both sides are listed, with values masked, and nothing is
+written.
 
-Note: data keys whose values contain newlines (e.g. PEM certificates) are
-written as-is and must remain intact in the editor. For cert-style values
-consider using --set-file in the update command instead.
+Multi-line values (e.g. PEM certificates) are shown as block scalars (|).
+Values that are not valid UTF-8 (keystores, .p12 files) are shown as
+!!binary base64 and are read-only: keep them as they are, delete the key, or
+replace the value with "@path" to load the file at path. Text values are
+taken literally, so "@dm1n!" is stored as written.
 
 Example:
   k8s-secret-manifest edit --input secret.yaml
@@ -36,11 +59,15 @@ func init() {
 
 	editCmd.Flags().StringP("output", "o", "",
 		"Output file path (default: same as --input)")
+	editCmd.Flags().Bool("backup", false,
+		"Keep the previous version of the output file as <output>.bak")
+	addSelectFlag(editCmd)
 }
 
 func runEdit(cmd *cobra.Command, _ []string) error {
 	inputPath, _ := cmd.Flags().GetString("input")
 	outputPath, _ := cmd.Flags().GetString("output")
+	backup, _ := cmd.Flags().GetBool("backup")
 	if outputPath == "" {
 		outputPath = inputPath
 	}
@@ -55,11 +82,23 @@ func runEdit(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	s, err := manifest.FromFile(safeInput)
+	// Remember what was loaded so a save can tell whether someone else
+	// changed the file while the editor was open.
+	orig, err := os.ReadFile(safeInput)
+	if err != nil {
+		return fmt.Errorf("load secret: %w", err)
+	}
+	origSum := sha256.Sum256(orig)
+	s, err := selectSecret(cmd, orig)
 	if err != nil {
 		return fmt.Errorf("load secret: %w", err)
 	}
 
+	buf, err := renderEditBuffer(s)
+	if err != nil {
+		return err
+	}
+
 	// Create a private temp directory (mode 0700) so other local users cannot
 	// observe or tamper with the decoded secret while the editor is open.
 	tmpDir, err := os.MkdirTemp("", "k8s-secret-edit-*")
@@ -67,58 +106,375 @@ func runEdit(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("create temp dir: %w", err)
 	}
 	defer func() { _ = os.RemoveAll(tmpDir) }()
+	tmpPath := filepath.Join(tmpDir, "secret.yaml")
 
-	tmpPath := filepath.Join(tmpDir, "secret.env")
-	tmpFile, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
-	if err != nil {
-		return fmt.Errorf("create temp file: %w", err)
+	// Reopen the editor until the buffer is valid, as kubectl edit does: the
+	// error goes in a comment at the top and the user's changes are kept.
+	var updated *corev1.Secret
+	var lastErr error
+	for {
+		edited, err := editInEditor(editor, tmpPath, buf)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(stripEditError(edited), stripEditError(buf)) {
+			if lastErr != nil {
+				return fmt.Errorf("edit cancelled, no valid changes were saved: %w", lastErr)
+			}
+			fmt.Fprintln(os.Stderr, "Edit cancelled, no changes made.")
+			return nil
+		}
+		updated, err = applyEditBuffer(s, edited)
+		if err == nil {
+			err = checkLimits(updated)
+		}
+		if err == nil {
+			break
+		}
+		lastErr = err
+		buf = withEditError(edited, err)
 	}
 
-	keys := make([]string, 0, len(s.Data))
-	for k := range s.Data {
-		keys = append(keys, k)
+	summary := editSummary(s, updated)
+	if len(summary) == 0 {
+		fmt.Fprintln(os.Stderr, "Edit cancelled, no changes made.")
+		return nil
+	}
+
+	return withExclusiveLock(outputPath, func() error {
+		current, err := os.ReadFile(safeInput)
+		if err != nil {
+			return fmt.Errorf("reload secret: %w", err)
+		}
+		var merged []string
+		if sha256.Sum256(current) != origSum {
+			theirs, err := selectSecret(cmd, current)
+			if err != nil {
+				return fmt.Errorf("reload secret: %w", err)
+			}
+			var conflicts []string
+			updated, conflicts = mergeEdit(s, updated, theirs)
+			if len(conflicts) > 0 {
+				return fmt.Errorf("%s changed while it was being edited and these changes conflict with yours:\n%s\n"+
+					"nothing was written; run edit again to apply your changes to the new version",
+					inputPath, strings.Join(conflicts, "\n"))
+			}
+			merged = editSummary(s, theirs)
+		}
+
+		if err := checkLimits(updated); err != nil {
+			return err
+		}
+		if backup {
+			if err := backupExisting(outputPath); err != nil {
+				return err
+			}
+		}
+
+		if err := writeSecretTo(outputPath, updated); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Updated %s\n", outputPath)
+		for _, line := range summary {
+			fmt.Fprintf(os.Stderr, "  %s\n", line)
+		}
+		if len(merged) > 0 {
+			fmt.Fprintf(os.Stderr, "Merged changes made to %s during the edit:\n", inputPath)
+			for _, line := range merged {
+				fmt.Fprintf(os.Stderr, "  %s\n", line)
+			}
+		}
+		return nil
+	})
+}
+
+// mergeEdit applies the edit from base to ours on top of theirs, a newer
+// version of the file written while the editor was open. Type, labels,
+// annotations, and data keys merge one key at a time; a key changed
+// differently on both sides is a conflict, reported with values masked.
+func mergeEdit(base, ours, theirs *corev1.Secret) (*corev1.Secret, []string) {
+	out := theirs.DeepCopy()
+	var conflicts []string
+
+	typ, keys := merge3(
+		map[string]string{"type": string(base.Type)},
+		map[string]string{"type": string(ours.Type)},
+		map[string]string{"type": string(theirs.Type)})
+	if len(keys) > 0 {
+		conflicts = append(conflicts, fmt.Sprintf("  type: was %s, yours %s, theirs %s", base.Type, ours.Type, theirs.Type))
+	}
+	out.Type = corev1.SecretType(typ["type"])
+
+	for _, field := range []struct {
+		name               string
+		base, ours, theirs map[string]string
+		set                *map[string]string
+	}{
+		{"labels", base.Labels, ours.Labels, theirs.Labels, &out.Labels},
+		{"annotations", base.Annotations, ours.Annotations, theirs.Annotations, &out.Annotations},
+	} {
+		m, keys := merge3(field.base, field.ours, field.theirs)
+		for _, k := range keys {
+			conflicts = append(conflicts, fmt.Sprintf("  %s %s: was %s, yours %s, theirs %s", field.name, k,
+				conflictValue(field.base, k, strconv.Quote), conflictValue(field.ours, k, strconv.Quote),
+				conflictValue(field.theirs, k, strconv.Quote)))
+		}
+		*field.set = nilIfEmpty(m)
 	}
-	sort.Strings(keys)
 
+	baseData, oursData, theirsData := stringData(base), stringData(ours), stringData(theirs)
+	data, keys := merge3(baseData, oursData, theirsData)
+	mask := func(v string) string { return maskValue([]byte(v)) }
 	for _, k := range keys {
-		if _, err := fmt.Fprintf(tmpFile, "%s=%s\n", k, string(s.Data[k])); err != nil {
-			_ = tmpFile.Close()
-			return fmt.Errorf("write temp file: %w", err)
+		conflicts = append(conflicts, fmt.Sprintf("  data %s: was %s, yours %s, theirs %s", k,
+			conflictValue(baseData, k, mask), conflictValue(oursData, k, mask), conflictValue(theirsData, k, mask)))
+	}
+	out.Data = make(map[string][]byte, len(data))
+	for k, v := range data {
+		out.Data[k] = []byte(v)
+	}
+	return out, conflicts
+}
+
+// merge3 merges the changes from base to ours and from base to theirs, key by
+// key; a missing key counts as a value. It returns the merged map and the
+// sorted keys changed differently on both sides, which keep theirs.
+func merge3[V comparable](base, ours, theirs map[string]V) (map[string]V, []string) {
+	keys := make(map[string]bool)
+	for _, m := range []map[string]V{base, ours, theirs} {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	out := make(map[string]V, len(keys))
+	var conflicts []string
+	for _, k := range slices.Sorted(maps.Keys(keys)) {
+		b, bok := base[k]
+		o, ook := ours[k]
+		t, tok := theirs[k]
+		pick, ok := t, tok
+		switch {
+		case ook == bok && o == b: // unchanged by the edit
+		case tok == bok && t == b, tok == ook && t == o:
+			pick, ok = o, ook
+		default:
+			conflicts = append(conflicts, k)
+		}
+		if ok {
+			out[k] = pick
 		}
 	}
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("close temp file: %w", err)
+	return out, conflicts
+}
+
+// conflictValue formats m[k] for a conflict report, or <absent>.
+func conflictValue(m map[string]string, k string, format func(string) string) string {
+	v, ok := m[k]
+	if !ok {
+		return "<absent>"
+	}
+	return format(v)
+}
+
+// stringData returns the data of s as strings, for comparison.
+func stringData(s *corev1.Secret) map[string]string {
+	out := make(map[string]string, len(s.Data))
+	for k, v := range s.Data {
+		out[k] = string(v)
+	}
+	return out
+}
+
+// editInEditor writes buf to path, opens it in editor, and returns the saved
+// content.
+func editInEditor(editor, path string, buf []byte) ([]byte, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("write temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("close temp file: %w", err)
 	}
 
-	// Launch editor.
-	editorCmd := exec.Command(editor, tmpPath) //nolint:gosec
+	editorCmd := exec.Command(editor, path) //nolint:gosec
 	editorCmd.Stdin = os.Stdin
 	editorCmd.Stdout = os.Stdout
 	editorCmd.Stderr = os.Stderr
 	if err := editorCmd.Run(); err != nil {
-		return fmt.Errorf("editor %q: %w", editor, err)
+		return nil, fmt.Errorf("editor %q: %w", editor, err)
 	}
 
-	// Re-read and re-encode.
-	edited, err := parseEnvFile(tmpPath)
+	edited, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("parse edited file: %w", err)
+		return nil, fmt.Errorf("read edited file: %w", err)
 	}
+	return edited, nil
+}
 
-	s.Data = make(map[string][]byte)
-	for k, v := range edited {
-		if err := validate.ValidateDataKey(k); err != nil {
-			return fmt.Errorf("edited file: %w", err)
+// editErrorPrefix starts the comment block that reports an invalid buffer.
+const editErrorPrefix = "# ERROR: "
+
+// withEditError puts err in a comment block at the top of buf, replacing the
+// block from a previous attempt.
+func withEditError(buf []byte, err error) []byte {
+	var sb strings.Builder
+	for i, line := range strings.Split(err.Error(), "\n") {
+		if i == 0 {
+			sb.WriteString(editErrorPrefix + line + "\n")
+		} else {
+			sb.WriteString("#   " + strings.TrimSpace(line) + "\n")
 		}
-		manifest.SetPlainValue(s, k, v)
 	}
+	sb.WriteString("#\n")
+	sb.Write(stripEditError(buf))
+	return []byte(sb.String())
+}
 
-	if err := writeSecretTo(outputPath, s); err != nil {
-		return err
+// stripEditError removes the comment block added by withEditError.
+func stripEditError(buf []byte) []byte {
+	if !bytes.HasPrefix(buf, []byte(editErrorPrefix)) {
+		return buf
+	}
+	for len(buf) > 0 {
+		line, rest, _ := bytes.Cut(buf, []byte("\n"))
+		if !bytes.HasPrefix(line, []byte(editErrorPrefix)) && !bytes.HasPrefix(line, []byte("#   ")) && string(line) != "#" {
+			break
+		}
+		buf = rest
+	}
+	return buf
+}
+
+// editSummary lists what an edit changed, one line per data key or metadata
+// field, with values masked. It is empty when nothing changed.
+func editSummary(from, to *corev1.Secret) []string {
+	d := compareSecrets(sourcedSecret{Secret: from}, sourcedSecret{Secret: to}, &redactor{}, false)
+	var lines []string
+	for _, m := range d.Metadata {
+		lines = append(lines, fmt.Sprintf("~ %s: %s → %s", m.Field, m.From, m.To))
+	}
+	if !maps.Equal(from.Labels, to.Labels) {
+		lines = append(lines, "~ labels")
+	}
+	if !maps.Equal(from.Annotations, to.Annotations) {
+		lines = append(lines, "~ annotations")
+	}
+	for _, c := range d.Changes {
+		switch c.Kind {
+		case report.KindAdded:
+			lines = append(lines, fmt.Sprintf("+ %s=%s", c.Key, maskValue(to.Data[c.Key])))
+		case report.KindRemoved:
+			lines = append(lines, fmt.Sprintf("- %s", c.Key))
+		case report.KindChanged:
+			lines = append(lines, fmt.Sprintf("~ %s=%s → %s", c.Key, maskValue(from.Data[c.Key]), maskValue(to.Data[c.Key])))
+		}
+	}
+	return lines
+}
+
+// editBuffer is the document opened in the editor.
+type editBuffer struct {
+	Type        string            `yaml:"type"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+	Data        map[string]string `yaml:"data"`
+}
+
+const editBufferHeader = `# Edit the Secret below, then save and exit to apply it.
+# Multi-line values use block scalars (|). !!binary values are read-only:
+# keep them, delete the key, or replace the value with "@path" to load a file.
+# Text values are taken literally, including ones that start with "@".
+`
+
+// renderEditBuffer returns the editable YAML document for s. Data values are
+// plain strings: the encoder writes multi-line text as block scalars and
+// invalid UTF-8 as !!binary base64.
+func renderEditBuffer(s *corev1.Secret) ([]byte, error) {
+	b := editBuffer{
+		Type:        string(s.Type),
+		Labels:      s.Labels,
+		Annotations: s.Annotations,
+		Data:        make(map[string]string, len(s.Data)),
+	}
+	if b.Labels == nil {
+		b.Labels = map[string]string{}
+	}
+	if b.Annotations == nil {
+		b.Annotations = map[string]string{}
+	}
+	for k, v := range s.Data {
+		b.Data[k] = string(v)
 	}
+	out, err := yaml.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("render edit buffer: %w", err)
+	}
+	return append([]byte(editBufferHeader), out...), nil
+}
 
-	fmt.Fprintf(os.Stderr, "Updated %s\n", outputPath)
-	return nil
+// applyEditBuffer returns a copy of s with the type, labels, annotations, and
+// data from an edited buffer. Binary values of s may only be kept, deleted,
+// or replaced with an @path file reference. Deleted keys lose their rotation
+// annotations.
+func applyEditBuffer(s *corev1.Secret, buf []byte) (*corev1.Secret, error) {
+	var b editBuffer
+	if err := yaml.UnmarshalStrict(buf, &b); err != nil {
+		return nil, err
+	}
+
+	out := s.DeepCopy()
+	out.Type = corev1.SecretType(b.Type)
+	if out.Type == "" {
+		out.Type = corev1.SecretTypeOpaque
+	}
+	out.Labels = nilIfEmpty(b.Labels)
+	out.Annotations = nilIfEmpty(b.Annotations)
+
+	out.Data = make(map[string][]byte, len(b.Data))
+	for _, k := range slices.Sorted(maps.Keys(b.Data)) {
+		v := b.Data[k]
+		if err := validate.ValidateDataKey(k); err != nil {
+			return nil, err
+		}
+		old, existed := s.Data[k]
+		switch {
+		case existed && v == string(old):
+			out.Data[k] = old
+		case existed && !utf8.Valid(old) && strings.HasPrefix(v, "@"):
+			path, err := safePath("data key "+k, strings.TrimPrefix(v, "@"))
+			if err != nil {
+				return nil, err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("data key %s: %w", k, err)
+			}
+			out.Data[k] = data
+		case existed && !utf8.Valid(old):
+			return nil, fmt.Errorf("data key %s is binary and read-only: keep it, delete it, or replace it with @path", k)
+		default:
+			out.Data[k] = []byte(v)
+		}
+	}
+	// Deleted keys lose their rotation annotations, as with update --delete-key.
+	for k := range s.Data {
+		if _, ok := out.Data[k]; !ok {
+			rotation.Forget(out, k)
+		}
+	}
+	return out, nil
+}
+
+func nilIfEmpty(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
 }
 
 // resolveEditor looks up the user's preferred editor from $EDITOR and returns
@@ -1,8 +1,18 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	"github.com/pbsladek/k8s-secret-manifest/internal/rotation"
 )
 
 func TestResolveEditor_FromEnv(t *testing.T) {
@@ -40,3 +50,272 @@ func TestResolveEditor_InvalidEditor(t *testing.T) {
 		t.Error("expected error for non-existent editor binary")
 	}
 }
+
+// ---- edit buffer ----
+
+func editTestSecret() *corev1.Secret {
+	s := manifest.NewSecret("s", "default")
+	s.Labels = map[string]string{"app": "web"}
+	s.Data = map[string][]byte{
+		"API_KEY":  []byte("abc"),
+		"TLS_CERT": []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"),
+		"KEYSTORE": {0x00, 0xfe, 0xff, 0x01},
+		"NUMBER":   []byte("5432"),
+		"SPACES":   []byte("  padded\t\n"),
+		"AT":       []byte("@literal"),
+		"CONTROL":  []byte("a\x00b\rc"),
+	}
+	return s
+}
+
+func TestEditBuffer_RoundTripUnchanged(t *testing.T) {
+	s := editTestSecret()
+	buf, err := renderEditBuffer(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"TLS_CERT: |", "KEYSTORE: !!binary", "app: web", "type: Opaque", "annotations: {}"} {
+		if !strings.Contains(string(buf), want) {
+			t.Errorf("buffer missing %q:\n%s", want, buf)
+		}
+	}
+	got, err := applyEditBuffer(s, buf)
+	if err != nil {
+		t.Fatalf("apply unchanged buffer: %v", err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("unchanged buffer altered the secret:\ngot  %+v\nwant %+v", got, s)
+	}
+}
+
+func TestEditBuffer_EditsMetadataAndData(t *testing.T) {
+	s := editTestSecret()
+	buf := `type: example.com/custom
+labels: {}
+annotations:
+  owner: team-a
+data:
+  API_KEY: changed
+  NEW_PEM: |
+    line1
+    line2
+  KEYSTORE: !!binary AP7/AQ==
+`
+	got, err := applyEditBuffer(s, []byte(buf))
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got.Type != "example.com/custom" || got.Labels != nil || got.Annotations["owner"] != "team-a" {
+		t.Errorf("metadata not applied: type=%q labels=%v annotations=%v", got.Type, got.Labels, got.Annotations)
+	}
+	if string(got.Data["API_KEY"]) != "changed" || string(got.Data["NEW_PEM"]) != "line1\nline2\n" {
+		t.Errorf("data not applied: %q", got.Data)
+	}
+	if _, ok := got.Data["TLS_CERT"]; ok {
+		t.Error("deleted key TLS_CERT still present")
+	}
+	if s.Data["API_KEY"] == nil || string(s.Data["API_KEY"]) != "abc" {
+		t.Error("applyEditBuffer modified its input")
+	}
+}
+
+func TestEditBuffer_BinaryReadOnly(t *testing.T) {
+	s := editTestSecret()
+	_, err := applyEditBuffer(s, []byte("data:\n  KEYSTORE: replaced\n"))
+	if err == nil || !strings.Contains(err.Error(), "KEYSTORE is binary and read-only") {
+		t.Errorf("expected read-only error, got %v", err)
+	}
+}
+
+func TestEditBuffer_FileReference(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("new.p12", []byte{0xde, 0xad, 0xbe, 0xef}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	s := editTestSecret()
+	got, err := applyEditBuffer(s, []byte("data:\n  KEYSTORE: \"@new.p12\"\n  AT: \"@literal\"\n  API_KEY: \"@dm1n!\"\n  NEW: \"@new.p12\"\n"))
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !bytes.Equal(got.Data["KEYSTORE"], []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("KEYSTORE = %x, want deadbeef", got.Data["KEYSTORE"])
+	}
+	if string(got.Data["AT"]) != "@literal" {
+		t.Errorf("unchanged @ value was treated as a file reference: %q", got.Data["AT"])
+	}
+	if string(got.Data["API_KEY"]) != "@dm1n!" || string(got.Data["NEW"]) != "@new.p12" {
+		t.Errorf("text values starting with @ must be literal: API_KEY %q, NEW %q", got.Data["API_KEY"], got.Data["NEW"])
+	}
+
+	if _, err := applyEditBuffer(s, []byte("data:\n  KEYSTORE: \"@../escape\"\n")); err == nil {
+		t.Error("expected error for a file reference outside the current directory")
+	}
+}
+
+func TestEditBuffer_DeleteForgetsRotation(t *testing.T) {
+	s := editTestSecret()
+	now := time.Now()
+	for _, k := range []string{"API_KEY", "NUMBER"} {
+		if err := rotation.Stamp(s, k, now, 24*time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := renderEditBuffer(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf = bytes.Replace(buf, []byte("  API_KEY: abc\n"), nil, 1)
+
+	got, err := applyEditBuffer(s, buf)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	statuses, err := rotation.Statuses(got, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 1 || statuses[0].Key != "NUMBER" {
+		t.Errorf("rotation statuses = %+v, want only NUMBER", statuses)
+	}
+}
+
+func TestEditBuffer_Rejects(t *testing.T) {
+	s := editTestSecret()
+	for name, buf := range map[string]string{
+		"syntax":       "data: [\n",
+		"unknown":      "lables: {}\n",
+		"duplicate":    "data:\n  A: 1\n  A: 2\n",
+		"invalid key":  "data:\n  bad key: x\n",
+		"missing file": "data:\n  KEYSTORE: \"@does-not-exist\"\n",
+	} {
+		if _, err := applyEditBuffer(s, []byte(buf)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestEditError_ReplacesPreviousBlock(t *testing.T) {
+	body := []byte("# Edit the Secret below\ndata:\n  A: b\n")
+	first := withEditError(body, errors.New("yaml: unmarshal errors:\n  line 2: bad"))
+	want := "# ERROR: yaml: unmarshal errors:\n#   line 2: bad\n#\n" + string(body)
+	if string(first) != want {
+		t.Errorf("got\n%s\nwant\n%s", first, want)
+	}
+	second := withEditError(first, errors.New("other"))
+	if string(second) != "# ERROR: other\n#\n"+string(body) {
+		t.Errorf("previous error block not replaced:\n%s", second)
+	}
+	if !bytes.Equal(stripEditError(second), body) {
+		t.Errorf("stripEditError = %q, want %q", stripEditError(second), body)
+	}
+	if !bytes.Equal(stripEditError(body), body) {
+		t.Error("stripEditError changed a buffer without an error block")
+	}
+}
+
+func TestEditSummary(t *testing.T) {
+	from := editTestSecret()
+	to := from.DeepCopy()
+	to.Type = "example.com/custom"
+	to.Labels = nil
+	to.Data["NEW"] = []byte("s3cr3t")
+	to.Data["API_KEY"] = []byte("rotated")
+	delete(to.Data, "NUMBER")
+
+	got := strings.Join(editSummary(from, to), "\n")
+	for _, want := range []string{
+		"~ type: Opaque → example.com/custom",
+		"~ labels",
+		"+ NEW=<masked len=6 sha256:",
+		"~ API_KEY=<masked len=3 sha256:",
+		"- NUMBER",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+	for _, secret := range []string{"s3cr3t", "rotated", "abc"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("summary leaks %q:\n%s", secret, got)
+		}
+	}
+	if lines := editSummary(from, from.DeepCopy()); len(lines) != 0 {
+		t.Errorf("unchanged secret: got %q, want no lines", lines)
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	base := map[string]string{"same": "1", "ours": "1", "theirs": "1", "both": "1", "gone": "1", "clash": "1"}
+	ours := map[string]string{"same": "1", "ours": "2", "theirs": "1", "both": "2", "clash": "2", "new": "x"}
+	theirs := map[string]string{"same": "1", "ours": "1", "theirs": "2", "both": "2", "gone": "1", "clash": "3"}
+
+	got, conflicts := merge3(base, ours, theirs)
+	want := map[string]string{"same": "1", "ours": "2", "theirs": "2", "both": "2", "clash": "3", "new": "x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(conflicts, []string{"clash"}) {
+		t.Errorf("conflicts = %v, want [clash]", conflicts)
+	}
+
+	// Deleting a key someone else changed is a conflict.
+	_, conflicts = merge3(map[string]string{"k": "1"}, map[string]string{}, map[string]string{"k": "2"})
+	if !reflect.DeepEqual(conflicts, []string{"k"}) {
+		t.Errorf("delete vs change: conflicts = %v, want [k]", conflicts)
+	}
+}
+
+func TestMergeEdit(t *testing.T) {
+	base := editTestSecret()
+	ours := base.DeepCopy()
+	ours.Data["API_KEY"] = []byte("mine")
+	ours.Labels = map[string]string{"app": "web", "tier": "front"}
+	theirs := base.DeepCopy()
+	theirs.Data["NUMBER"] = []byte("9999")
+	theirs.Annotations = map[string]string{"rotated": "yes"}
+
+	got, conflicts := mergeEdit(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", conflicts)
+	}
+	if string(got.Data["API_KEY"]) != "mine" || string(got.Data["NUMBER"]) != "9999" {
+		t.Errorf("data = API_KEY %q, NUMBER %q", got.Data["API_KEY"], got.Data["NUMBER"])
+	}
+	if !bytes.Equal(got.Data["KEYSTORE"], base.Data["KEYSTORE"]) {
+		t.Errorf("binary value changed: %v", got.Data["KEYSTORE"])
+	}
+	if got.Labels["tier"] != "front" || got.Annotations["rotated"] != "yes" {
+		t.Errorf("labels %v, annotations %v", got.Labels, got.Annotations)
+	}
+}
+
+func TestMergeEdit_Conflicts(t *testing.T) {
+	base := editTestSecret()
+	ours := base.DeepCopy()
+	ours.Data["API_KEY"] = []byte("mine")
+	ours.Labels = map[string]string{"app": "api"}
+	delete(ours.Data, "NUMBER")
+	theirs := base.DeepCopy()
+	theirs.Data["API_KEY"] = []byte("other")
+	theirs.Labels = map[string]string{"app": "worker"}
+	theirs.Data["NUMBER"] = []byte("9999")
+
+	_, conflicts := mergeEdit(base, ours, theirs)
+	got := strings.Join(conflicts, "\n")
+	for _, want := range []string{
+		`labels app: was "web", yours "api", theirs "worker"`,
+		"data API_KEY: was <masked len=3 sha256:",
+		"data NUMBER: was <masked len=4 sha256:",
+		"yours <absent>, theirs <masked len=4",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("conflicts missing %q:\n%s", want, got)
+		}
+	}
+	for _, secret := range []string{"mine", "other", "abc", "9999"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("conflicts leak %q:\n%s", secret, got)
+		}
+	}
+}
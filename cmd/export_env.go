@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"encoding/base64"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/pbsladek/k8s-secret-manifest/internal/dotenv"
 	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
 	"github.com/spf13/cobra"
 )
@@ -16,7 +18,9 @@ var exportEnvCmd = &cobra.Command{
 	Long: `Decode a Kubernetes Secret manifest and write it as a .env file.
 
 Keys are sorted alphabetically. Values that contain spaces, quotes, or other
-shell-significant characters are automatically wrapped in double quotes.
+shell-significant characters are automatically wrapped in double quotes, and
+binary values (not valid UTF-8) are written as "KEY=!!binary <base64>".
+from-env reads the file back to exactly the same values.
 
 Example:
   k8s-secret-manifest export-env --input secret.yaml --output .env
@@ -62,20 +66,26 @@ func runExportEnv(cmd *cobra.Command, _ []string) error {
 		_, err := fmt.Print(sb.String())
 		return err
 	}
-	return os.WriteFile(outputPath, []byte(sb.String()), 0600)
+	return writeOutput(outputPath, []byte(sb.String()))
 }
 
 // quoteEnvValue wraps val in double quotes when it contains characters that
-// would confuse .env parsers. Double quotes and backslashes inside are escaped.
+// would confuse .env parsers. Backslashes, double quotes, dollar signs, and
+// carriage returns inside are escaped; newlines are kept, so multi-line
+// values stay readable. Values that are not valid UTF-8 are written as
+// "!!binary <base64>". dotenv.Parse reads the result back as val.
 func quoteEnvValue(val string) string {
+	if !utf8.ValidString(val) {
+		return dotenv.BinaryMarker + " " + base64.StdEncoding.EncodeToString([]byte(val))
+	}
 	if !needsEnvQuoting(val) {
 		return val
 	}
-	escaped := strings.ReplaceAll(val, `\`, `\\`)
-	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
-	return `"` + escaped + `"`
+	return `"` + envEscaper.Replace(val) + `"`
 }
 
+var envEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\r", `\r`)
+
 func needsEnvQuoting(val string) bool {
 	return strings.ContainsAny(val, " \t\n\r\"'#$\\=;,")
 }
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// preserveOwner gives f the uid/gid recorded in info. This is best effort:
+// unprivileged users can usually only chown to themselves, in which case the
+// replaced file ends up owned by the invoking user, as with any editor save.
+func preserveOwner(f *os.File, info os.FileInfo) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	_ = f.Chown(int(st.Uid), int(st.Gid))
+}
+
+// syncDir fsyncs a directory so a preceding rename is durable.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package cmd
+
+import "os"
+
+// preserveOwner is a no-op on Windows; files inherit the directory's ACL.
+func preserveOwner(_ *os.File, _ os.FileInfo) {}
+
+// syncDir is a no-op on Windows; directories cannot be opened for fsync.
+func syncDir(_ string) {}
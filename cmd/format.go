@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// Values of the global --output-format flag.
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// outputFormat returns the validated global --output-format value.
+func outputFormat(cmd *cobra.Command) (string, error) {
+	f, _ := cmd.Root().PersistentFlags().GetString("output-format")
+	switch f {
+	case formatText, formatJSON, formatYAML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("--output-format: unknown format %q: use text, json, or yaml", f)
+	}
+}
+
+// printStructured writes v to stdout as indented JSON or as YAML.
+func printStructured(format string, v any) error {
+	var (
+		data []byte
+		err  error
+	)
+	if format == formatYAML {
+		data, err = yaml.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("encode %s output: %w", format, err)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
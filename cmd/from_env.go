@@ -1,13 +1,12 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/pbsladek/k8s-secret-manifest/internal/dotenv"
 	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
 	"github.com/pbsladek/k8s-secret-manifest/internal/validate"
 	"github.com/spf13/cobra"
@@ -18,9 +17,13 @@ var fromEnvCmd = &cobra.Command{
 	Short: "Generate a Secret manifest from a .env file",
 	Long: `Generate a Kubernetes Secret manifest by reading key=value pairs from a .env file.
 
-Blank lines and lines starting with # are ignored.
-The "export " prefix is stripped if present.
-Values surrounded by single or double quotes are unquoted.
+Blank lines and lines starting with # are ignored, as are comments after
+whitespace at the end of unquoted values. The "export " prefix is stripped if
+present. Single-quoted values are taken literally; double-quoted values may
+span lines and understand the escapes \n, \r, \t, \", \\, and \$. ${VAR} in
+unquoted and double-quoted values expands to a key set earlier in the file,
+or else to an environment variable. A key may be set only once.
+"KEY=!!binary <base64>" sets a binary value, as written by export-env.
 
 Example:
   k8s-secret-manifest from-env \
@@ -44,6 +47,8 @@ func init() {
 	_ = fromEnvCmd.MarkFlagRequired("env-file")
 
 	fromEnvCmd.Flags().StringP("output", "o", "", "Output file path (default: stdout)")
+	fromEnvCmd.Flags().String("emit", manifest.EmitData,
+		"Output encoding: data (base64) or string-data (readable stringData:, binary values stay in data:)")
 
 	fromEnvCmd.Flags().StringP("type", "t", "",
 		"Secret type (default: Opaque)")
@@ -67,6 +72,12 @@ func runFromEnv(cmd *cobra.Command, _ []string) error {
 	annotations, _ := cmd.Flags().GetStringArray("annotation")
 	immutable, _ := cmd.Flags().GetBool("immutable")
 	sets, _ := cmd.Flags().GetStringArray("set")
+	emitFlag, _ := cmd.Flags().GetString("emit")
+
+	emit, err := manifest.ParseEmitMode(emitFlag)
+	if err != nil {
+		return fmt.Errorf("--emit: %w", err)
+	}
 
 	safeEnvFile, err := safePath("--env-file", envFile)
 	if err != nil {
@@ -124,61 +135,16 @@ func runFromEnv(cmd *cobra.Command, _ []string) error {
 		manifest.SetPlainValue(s, k, v)
 	}
 
-	yamlBytes, err := manifest.ToYAML(s)
-	if err != nil {
-		return err
-	}
-
-	return writeOutput(outputPath, yamlBytes)
+	return writeSecretAs(outputPath, s, emit)
 }
 
-// parseEnvFile reads a .env file and returns key=value pairs.
-// Blank lines and # comments are skipped. "export " prefix is stripped.
-// Values surrounded by matching single or double quotes are unquoted.
+// parseEnvFile reads a .env file and returns its key=value pairs (see
+// package dotenv for the dialect). ${VAR} references fall back to the
+// process environment.
 func parseEnvFile(path string) (map[string]string, error) {
-	f, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = f.Close() }()
-
-	result := make(map[string]string)
-	scanner := bufio.NewScanner(f)
-	lineNum := 0
-
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		line = strings.TrimPrefix(line, "export ")
-
-		idx := strings.IndexByte(line, '=')
-		if idx < 0 {
-			return nil, fmt.Errorf("line %d: expected KEY=value, got %q", lineNum, line)
-		}
-
-		key := strings.TrimSpace(line[:idx])
-		if key == "" {
-			return nil, fmt.Errorf("line %d: empty key", lineNum)
-		}
-
-		val := unquote(line[idx+1:])
-		result[key] = val
-	}
-
-	return result, scanner.Err()
-}
-
-// unquote strips a matching pair of surrounding single or double quotes.
-func unquote(s string) string {
-	if len(s) >= 2 {
-		if (s[0] == '"' && s[len(s)-1] == '"') || (s[0] == '\'' && s[len(s)-1] == '\'') {
-			return s[1 : len(s)-1]
-		}
-	}
-	return s
+	return dotenv.Parse(data, os.LookupEnv)
 }
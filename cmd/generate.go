@@ -31,6 +31,11 @@ TLS secret (type set automatically):
   k8s-secret-manifest generate --name tls-secret \
     --tls-cert ./tls.crt --tls-key ./tls.key
 
+Issued TLS secret (self-signed, or signed by a CA from a Secret or PEM pair):
+  k8s-secret-manifest generate --name web-tls \
+    --tls-cn web.internal --tls-san web.internal --tls-san 10.0.0.5 \
+    --tls-ca-secret ca-secret.yaml --tls-include-ca
+
 Docker registry pull secret (type set automatically):
   k8s-secret-manifest generate --name registry-secret \
     --docker-server ghcr.io \
@@ -42,7 +47,35 @@ Paired index-list (two data keys whose values are semicolon-separated and index-
     --entries-key  PGPOOL_BACKEND_PASSWORD_USERS \
     --entries-val  PGPOOL_BACKEND_PASSWORD_PASSWORDS \
     --entry "alice:secretpass" \
-    --entry "bob:otherpass"`,
+    --entry "bob:otherpass"
+
+Index-matched table with more columns (repeat --entries-val; one value per column):
+  k8s-secret-manifest generate --name db-users \
+    --entries-key DB_USERS \
+    --entries-val DB_PASSWORDS --entries-val DB_NAMES \
+    --entry "alice:secretpass:orders" \
+    --entry "bob:otherpass:reporting"
+
+Random values (password flags as for rotate):
+  k8s-secret-manifest generate --name db-secret \
+    --random DB_PASSWORD --charset printable --min-symbols 2 \
+    --random ADMIN_PASSPHRASE --words 6
+
+Key material (public halves written to their own keys):
+  k8s-secret-manifest generate --name jwt-keys \
+    --keygen jwt.key=ecdsa:p256 --public-key jwt.key=jwt.pub \
+    --keygen AES_KEY=bytes:32
+
+SSH deploy key (type set automatically):
+  k8s-secret-manifest generate --name deploy-key \
+    --keygen ssh-privatekey=ssh-ed25519 --public-key ssh-privatekey=ssh-publickey
+
+Readable output for review (stringData: instead of base64 data:):
+  k8s-secret-manifest generate --name my-secret \
+    --set API_KEY=mysecret --emit string-data
+
+With --policy, the generated Secret is checked against the policy file's
+rules and nothing is written if an error-severity rule fails.`,
 	RunE: runGenerate,
 }
 
@@ -54,6 +87,12 @@ func init() {
 		"key=value pair; repeatable (e.g. --set API_KEY=abc)")
 	generateCmd.Flags().StringArrayP("set-file", "f", nil,
 		"key=filepath pair; file content becomes the value; repeatable (e.g. --set-file CERT=./tls.crt)")
+	generateCmd.Flags().StringArray("random", nil,
+		"Key to fill with a cryptographically random value; repeatable (see the password flags)")
+	addPasswordFlags(generateCmd, "", "")
+	addKeygenFlags(generateCmd)
+	addTLSIssueFlags(generateCmd)
+	addTLSCAFlags(generateCmd)
 
 	generateCmd.Flags().StringP("type", "t", "",
 		`Secret type (default: Opaque). Common values:
@@ -86,14 +125,19 @@ func init() {
 	// paired index-list
 	generateCmd.Flags().StringP("entries-key", "K", "",
 		"Data key name holding the delimiter-separated identifier list")
-	generateCmd.Flags().StringP("entries-val", "V", "",
-		"Data key name holding the delimiter-separated value list")
+	generateCmd.Flags().StringArrayP("entries-val", "V", nil,
+		"Data key name holding a delimiter-separated value list; repeat for each value column")
 	generateCmd.Flags().StringArrayP("entry", "e", nil,
-		"key:value entry for the paired lists; repeatable (e.g. --entry alice:pass)")
+		"key:value entry for the paired lists, with one value per --entries-val; repeatable (e.g. --entry alice:pass). "+
+			"With several --entries-val, write a colon inside a value as \\:")
 	generateCmd.Flags().StringP("separator", "S", ";",
 		"Separator used between entries in the list values (default: \";\")")
+	addQuoteFlag(generateCmd)
 
 	generateCmd.Flags().StringP("output", "o", "", "Output file path (default: stdout)")
+	generateCmd.Flags().String("emit", manifest.EmitData,
+		"Output encoding: data (base64) or string-data (readable stringData:, binary values stay in data:)")
+	addPolicyFlag(generateCmd)
 }
 
 func runGenerate(cmd *cobra.Command, _ []string) error {
@@ -101,6 +145,7 @@ func runGenerate(cmd *cobra.Command, _ []string) error {
 	namespace, _ := cmd.Root().PersistentFlags().GetString("namespace")
 	sets, _ := cmd.Flags().GetStringArray("set")
 	setFiles, _ := cmd.Flags().GetStringArray("set-file")
+	randomKeys, _ := cmd.Flags().GetStringArray("random")
 	secretType, _ := cmd.Flags().GetString("type")
 	labels, _ := cmd.Flags().GetStringArray("label")
 	annotations, _ := cmd.Flags().GetStringArray("annotation")
@@ -112,10 +157,19 @@ func runGenerate(cmd *cobra.Command, _ []string) error {
 	dockerPassword, _ := cmd.Flags().GetString("docker-password")
 	dockerEmail, _ := cmd.Flags().GetString("docker-email")
 	entriesKey, _ := cmd.Flags().GetString("entries-key")
-	entriesVal, _ := cmd.Flags().GetString("entries-val")
+	entriesVals, _ := cmd.Flags().GetStringArray("entries-val")
 	entryFlags, _ := cmd.Flags().GetStringArray("entry")
-	sep, _ := cmd.Flags().GetString("separator")
 	outputPath, _ := cmd.Flags().GetString("output")
+	emitFlag, _ := cmd.Flags().GetString("emit")
+
+	emit, err := manifest.ParseEmitMode(emitFlag)
+	if err != nil {
+		return fmt.Errorf("--emit: %w", err)
+	}
+	pol, err := loadPolicy(cmd)
+	if err != nil {
+		return err
+	}
 
 	s := manifest.NewSecret(name, namespace)
 
@@ -162,14 +216,50 @@ func runGenerate(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	// Random values
+	if len(randomKeys) > 0 {
+		gen, err := passwordGenerator(cmd)
+		if err != nil {
+			return err
+		}
+		for _, k := range randomKeys {
+			if err := validate.ValidateDataKey(k); err != nil {
+				return fmt.Errorf("--random: %w", err)
+			}
+			val, err := gen.Generate()
+			if err != nil {
+				return fmt.Errorf("generate value for %q: %w", k, err)
+			}
+			manifest.SetPlainValue(s, k, val)
+		}
+		fmt.Fprintf(os.Stderr, "Generated %d random value(s) (%.0f bits of entropy each)\n",
+			len(randomKeys), gen.EntropyBits())
+	}
+
+	// Key material
+	targets, err := keygenTargets(cmd)
+	if err != nil {
+		return err
+	}
+	if err := applyKeygen(s, targets, secretType == ""); err != nil {
+		return err
+	}
+
 	// TLS helper
 	if tlsCert != "" || tlsKey != "" {
 		if tlsCert == "" || tlsKey == "" {
 			return fmt.Errorf("--tls-cert and --tls-key must both be provided")
 		}
+		if tlsIssueRequested(cmd) {
+			return fmt.Errorf("--tls-cert/--tls-key cannot be combined with issuing a certificate (--tls-cn, --tls-san, ...)")
+		}
 		if err := applyTLS(s, tlsCert, tlsKey, secretType); err != nil {
 			return err
 		}
+	} else if tlsIssueRequested(cmd) {
+		if err := applyTLSIssue(cmd, s, secretType); err != nil {
+			return err
+		}
 	}
 
 	// Docker registry helper
@@ -183,31 +273,47 @@ func runGenerate(cmd *cobra.Command, _ []string) error {
 	}
 
 	// paired index-list
-	if entriesKey != "" || entriesVal != "" || len(entryFlags) > 0 {
-		if entriesKey == "" || entriesVal == "" {
+	if entriesKey != "" || len(entriesVals) > 0 || len(entryFlags) > 0 {
+		if entriesKey == "" || len(entriesVals) == 0 {
 			return fmt.Errorf("--entries-key and --entries-val are both required when using --entry flags")
 		}
 		if err := validate.ValidateDataKey(entriesKey); err != nil {
 			return fmt.Errorf("--entries-key: %w", err)
 		}
-		if err := validate.ValidateDataKey(entriesVal); err != nil {
-			return fmt.Errorf("--entries-val: %w", err)
+		for _, v := range entriesVals {
+			if err := validate.ValidateDataKey(v); err != nil {
+				return fmt.Errorf("--entries-val: %w", err)
+			}
+		}
+		codec, err := entryCodec(cmd)
+		if err != nil {
+			return err
+		}
+		rows, err := parseEntryFlags(entryFlags, len(entriesVals))
+		if err != nil {
+			return err
 		}
-		entries, err := parseEntryFlags(entryFlags)
+		table, err := entrylist.NewTable(append([]string{entriesKey}, entriesVals...)...)
 		if err != nil {
+			return fmt.Errorf("--entries-key/--entries-val: %w", err)
+		}
+		for _, row := range rows {
+			if err := codec.CheckRow(table, row); err != nil {
+				return quoteHint(err)
+			}
+			if table, err = table.Add(row); err != nil {
+				return err
+			}
+		}
+		if err := storeTable(s, codec, table); err != nil {
 			return err
 		}
-		keysVal, valsVal := entrylist.Serialize(entries, sep)
-		manifest.SetPlainValue(s, entriesKey, keysVal)
-		manifest.SetPlainValue(s, entriesVal, valsVal)
 	}
 
-	yamlBytes, err := manifest.ToYAML(s)
-	if err != nil {
+	if err := enforcePolicy(pol, s); err != nil {
 		return err
 	}
-
-	return writeOutput(outputPath, yamlBytes)
+	return writeSecretAs(outputPath, s, emit)
 }
 
 // applySetFiles reads key=filepath pairs and stores the file contents as values.
@@ -302,29 +408,60 @@ func applyDockerRegistry(s *corev1.Secret, server, username, password, email, ex
 	return nil
 }
 
-// parseEntryFlags parses --entry "key:value" flags.
-// The first ":" is the delimiter; values may contain colons.
-func parseEntryFlags(flags []string) ([]entrylist.Entry, error) {
-	entries := make([]entrylist.Entry, 0, len(flags))
+// parseEntryFlags parses --entry "key:value[:value...]" flags into table
+// rows of a key and n values. With one value column, the first colon is the
+// delimiter and the value may contain colons. With several, every unescaped
+// colon is a delimiter, "\:" is a literal colon and "\\" a literal
+// backslash; an entry with more fields than columns is rejected rather than
+// guessed at.
+func parseEntryFlags(flags []string, n int) ([][]string, error) {
+	rows := make([][]string, 0, len(flags))
 	seen := make(map[string]bool)
 
 	for _, f := range flags {
-		idx := strings.IndexByte(f, ':')
-		if idx < 0 {
-			return nil, fmt.Errorf("invalid --entry %q: expected format key:value", f)
+		var row []string
+		if n == 1 {
+			row = strings.SplitN(f, ":", 2)
+		} else {
+			row = splitEntry(f)
+		}
+		if len(row) < n+1 {
+			return nil, fmt.Errorf("invalid --entry %q: expected format key%s", f, strings.Repeat(":value", n))
 		}
-		key := f[:idx]
-		value := f[idx+1:]
+		key := row[0]
 		if key == "" {
 			return nil, fmt.Errorf("invalid --entry %q: key must not be empty", f)
 		}
+		if len(row) > n+1 {
+			return nil, fmt.Errorf("invalid --entry for key %q: %d values for %d --entries-val column(s); write a colon inside a value as \\:",
+				key, len(row)-1, n)
+		}
 		if seen[key] {
 			return nil, fmt.Errorf("duplicate --entry key %q", key)
 		}
 		seen[key] = true
-		entries = append(entries, entrylist.Entry{Key: key, Value: value})
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// splitEntry splits f at unescaped colons, resolving "\:" and "\\".
+func splitEntry(f string) []string {
+	var fields []string
+	var sb strings.Builder
+	for i := 0; i < len(f); i++ {
+		switch c := f[i]; {
+		case c == '\\' && i+1 < len(f) && (f[i+1] == ':' || f[i+1] == '\\'):
+			i++
+			sb.WriteByte(f[i])
+		case c == ':':
+			fields = append(fields, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(c)
+		}
 	}
-	return entries, nil
+	return append(fields, sb.String())
 }
 
 // parseKeyValuePairs parses a slice of "key=value" strings into a map.
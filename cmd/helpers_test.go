@@ -3,6 +3,8 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -87,56 +89,98 @@ func TestParseKeyValuePairs_LastWins(t *testing.T) {
 // ---- parseEntryFlags ----
 
 func TestParseEntryFlags_HappyPath(t *testing.T) {
-	entries, err := parseEntryFlags([]string{"alice:pass1", "bob:pass2"})
+	rows, err := parseEntryFlags([]string{"alice:pass1", "bob:pass2"}, 1)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(entries) != 2 {
-		t.Fatalf("want 2 entries, got %d", len(entries))
+	if len(rows) != 2 {
+		t.Fatalf("want 2 rows, got %d", len(rows))
 	}
-	if entries[0].Key != "alice" || entries[0].Value != "pass1" {
-		t.Errorf("entries[0] = %+v", entries[0])
+	if rows[0][0] != "alice" || rows[0][1] != "pass1" {
+		t.Errorf("rows[0] = %q", rows[0])
 	}
 }
 
 func TestParseEntryFlags_ValueContainsColon(t *testing.T) {
-	entries, err := parseEntryFlags([]string{"url:https://example.com"})
+	rows, err := parseEntryFlags([]string{"url:https://example.com"}, 1)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if entries[0].Value != "https://example.com" {
-		t.Errorf("value = %q, want \"https://example.com\"", entries[0].Value)
+	if rows[0][1] != "https://example.com" {
+		t.Errorf("value = %q, want \"https://example.com\"", rows[0][1])
+	}
+}
+
+func TestParseEntryFlags_MultipleColumns(t *testing.T) {
+	rows, err := parseEntryFlags([]string{"alice:pass1:orders", `bob:pass2:http\://db\:5432`}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"alice", "pass1", "orders"}; !slices.Equal(rows[0], want) {
+		t.Errorf("rows[0] = %q, want %q", rows[0], want)
+	}
+	if want := []string{"bob", "pass2", "http://db:5432"}; !slices.Equal(rows[1], want) {
+		t.Errorf("rows[1] = %q, want %q", rows[1], want)
+	}
+}
+
+func TestParseEntryFlags_ColonInMiddleColumn(t *testing.T) {
+	rows, err := parseEntryFlags([]string{`alice:p\:ss:orders`, `bob:back\\slash:x`}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"alice", "p:ss", "orders"}; !slices.Equal(rows[0], want) {
+		t.Errorf("rows[0] = %q, want %q", rows[0], want)
+	}
+	if want := []string{"bob", `back\slash`, "x"}; !slices.Equal(rows[1], want) {
+		t.Errorf("rows[1] = %q, want %q", rows[1], want)
+	}
+
+	// Unescaped, the colon could belong to either value.
+	_, err = parseEntryFlags([]string{"alice:p:ss:orders"}, 2)
+	if err == nil || !strings.Contains(err.Error(), `\:`) {
+		t.Errorf("expected an error pointing at \\:, got %v", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "p:ss") {
+		t.Errorf("error leaks the value: %v", err)
+	}
+}
+
+func TestParseEntryFlags_TooFewValues(t *testing.T) {
+	_, err := parseEntryFlags([]string{"alice:pass1"}, 2)
+	if err == nil || !strings.Contains(err.Error(), "key:value:value") {
+		t.Errorf("expected key:value:value format error, got %v", err)
 	}
 }
 
 func TestParseEntryFlags_MissingColon(t *testing.T) {
-	_, err := parseEntryFlags([]string{"nocolon"})
+	_, err := parseEntryFlags([]string{"nocolon"}, 1)
 	if err == nil {
 		t.Error("expected error for missing ':'")
 	}
 }
 
 func TestParseEntryFlags_EmptyKey(t *testing.T) {
-	_, err := parseEntryFlags([]string{":value"})
+	_, err := parseEntryFlags([]string{":value"}, 1)
 	if err == nil {
 		t.Error("expected error for empty key")
 	}
 }
 
 func TestParseEntryFlags_DuplicateKey(t *testing.T) {
-	_, err := parseEntryFlags([]string{"alice:pass1", "alice:pass2"})
+	_, err := parseEntryFlags([]string{"alice:pass1", "alice:pass2"}, 1)
 	if err == nil {
 		t.Error("expected error for duplicate key")
 	}
 }
 
 func TestParseEntryFlags_Empty(t *testing.T) {
-	entries, err := parseEntryFlags(nil)
+	rows, err := parseEntryFlags(nil, 1)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(entries) != 0 {
-		t.Errorf("want empty, got %v", entries)
+	if len(rows) != 0 {
+		t.Errorf("want empty, got %v", rows)
 	}
 }
 
@@ -239,6 +283,18 @@ func TestParseEnvFile_MissingEquals(t *testing.T) {
 	}
 }
 
+func TestParseEnvFile_InterpolatesFromEnvironment(t *testing.T) {
+	t.Setenv("K8S_SECRET_MANIFEST_TEST_HOST", "db.example")
+	path := writeEnvFile(t, "PORT=5432\nURL=postgres://${K8S_SECRET_MANIFEST_TEST_HOST}:${PORT}\n")
+	pairs, err := parseEnvFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pairs["URL"] != "postgres://db.example:5432" {
+		t.Errorf("URL = %q, want \"postgres://db.example:5432\"", pairs["URL"])
+	}
+}
+
 func TestParseEnvFile_NotFound(t *testing.T) {
 	_, err := parseEnvFile("/nonexistent/.env")
 	if err == nil {
@@ -246,40 +302,183 @@ func TestParseEnvFile_NotFound(t *testing.T) {
 	}
 }
 
-// ---- unquote ----
+// ---- expandInputs ----
 
-func TestUnquote_DoubleQuotes(t *testing.T) {
-	if got := unquote(`"hello"`); got != "hello" {
-		t.Errorf("got %q, want \"hello\"", got)
+func TestExpandInputs_SingleFile(t *testing.T) {
+	files, err := expandInputs("--input", "secret.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0] != "secret.yaml" {
+		t.Errorf("got %v, want [secret.yaml]", files)
 	}
 }
 
-func TestUnquote_SingleQuotes(t *testing.T) {
-	if got := unquote("'hello'"); got != "hello" {
-		t.Errorf("got %q, want \"hello\"", got)
+func TestExpandInputs_DirectoryRecursive(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "nested"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"b.yaml", "a.yml", "nested/c.yaml", "readme.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := expandInputs("--input", dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "a.yml"),
+		filepath.Join(dir, "b.yaml"),
+		filepath.Join(dir, "nested", "c.yaml"),
+	}
+	if len(files) != len(want) {
+		t.Fatalf("got %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("files[%d] = %q, want %q", i, files[i], want[i])
+		}
 	}
 }
 
-func TestUnquote_NoQuotes(t *testing.T) {
-	if got := unquote("hello"); got != "hello" {
-		t.Errorf("got %q, want \"hello\"", got)
+func TestExpandInputs_GlobNoMatch(t *testing.T) {
+	if _, err := expandInputs("--input", filepath.Join(t.TempDir(), "*.yaml")); err == nil {
+		t.Error("expected error when glob matches nothing")
 	}
 }
 
-func TestUnquote_MismatchedQuotes(t *testing.T) {
-	if got := unquote(`"hello'`); got != `"hello'` {
-		t.Errorf("mismatched quotes should not be stripped, got %q", got)
+func TestExpandInputs_EmptyDirectory(t *testing.T) {
+	if _, err := expandInputs("--input", t.TempDir()); err == nil {
+		t.Error("expected error for directory without YAML files")
 	}
 }
 
-func TestUnquote_EmptyString(t *testing.T) {
-	if got := unquote(""); got != "" {
-		t.Errorf("got %q, want empty", got)
+func TestExpandInputs_Traversal(t *testing.T) {
+	if _, err := expandInputs("--input", "../*.yaml"); err == nil {
+		t.Error("expected error for pattern escaping the working directory")
 	}
 }
 
-func TestUnquote_OnlyQuotes(t *testing.T) {
-	if got := unquote(`""`); got != "" {
-		t.Errorf("got %q, want empty", got)
+// ---- writeFileAtomic / backupExisting ----
+
+func TestWriteFileAtomic_NewFileIs0600(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.yaml")
+	if err := writeFileAtomic(path, []byte("data")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %o, want 600", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileAtomic_PreservesModeAndLeavesNoTemp(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret.yaml")
+	if err := os.WriteFile(path, []byte("old"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(path, []byte("new")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	if string(got) != "new" {
+		t.Errorf("content = %q, want \"new\"", got)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %o, want 640", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("temp files left behind: %v", entries)
+	}
+}
+
+func TestWriteFileAtomic_FollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real.yaml")
+	link := filepath.Join(dir, "link.yaml")
+	if err := os.WriteFile(target, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := writeFileAtomic(link, []byte("new")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fi, _ := os.Lstat(link); fi.Mode()&os.ModeSymlink == 0 {
+		t.Error("symlink was replaced by a regular file")
+	}
+	if got, _ := os.ReadFile(target); string(got) != "new" {
+		t.Errorf("target content = %q, want \"new\"", got)
+	}
+}
+
+func TestWriteFileAtomic_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "secret.yaml")
+	if err := writeFileAtomic(path, []byte("x")); err == nil {
+		t.Error("expected error when the parent directory does not exist")
+	}
+}
+
+func TestBackupExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.yaml")
+	if err := os.WriteFile(path, []byte("v1"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupExisting(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatalf("read backup: %v", err)
+	}
+	if string(got) != "v1" {
+		t.Errorf("backup content = %q, want \"v1\"", got)
+	}
+}
+
+func TestBackupExisting_MissingFileIsNoop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.yaml")
+	if err := backupExisting(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Error("no backup should be created for a missing file")
+	}
+}
+
+// ---- redactor ----
+
+func TestMaskValue(t *testing.T) {
+	got := maskValue([]byte("secret-value"))
+	want := "<masked len=12 sha256:31160254>"
+	if got != want {
+		t.Errorf("maskValue = %q, want %q", got, want)
+	}
+}
+
+func TestRedactor_RevealKey(t *testing.T) {
+	r := &redactor{keys: map[string]bool{"A": true}}
+	if got := r.value("A", []byte("x")); got != "x" {
+		t.Errorf("revealed key: got %q", got)
+	}
+	if got := r.value("B", []byte("x")); got == "x" {
+		t.Error("unrevealed key should be masked")
+	}
+	r.revealAll = true
+	if got := r.value("B", []byte("x")); got != "x" {
+		t.Errorf("--reveal: got %q", got)
 	}
 }
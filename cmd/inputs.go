@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// sourcedSecret is a Secret together with the file it was read from.
+type sourcedSecret struct {
+	Path   string
+	Secret *corev1.Secret
+}
+
+// expandInputs resolves an --input style argument into a sorted list of files.
+// A directory is walked recursively for *.yaml and *.yml files, a pattern
+// containing glob metacharacters is expanded with filepath.Glob, and anything
+// else is treated as a single file.
+func expandInputs(flag, pattern string) ([]string, error) {
+	safe, err := safePath(flag, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.ContainsAny(pattern, "*?[") {
+		matches, err := filepath.Glob(safe)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", flag, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no files match %q", flag, pattern)
+		}
+		for _, m := range matches {
+			if _, err := safePath(flag, m); err != nil {
+				return nil, err
+			}
+		}
+		sort.Strings(matches)
+		return matches, nil
+	}
+
+	if !isDir(safe) {
+		return []string{safe}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(safe, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: walk %q: %w", flag, safe, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: no .yaml or .yml files in %q", flag, pattern)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// loadSecrets expands pattern and returns every Secret found in the matching
+// files, in file and stream order. Non-Secret documents are skipped. When the
+// global --namespace flag was set explicitly, or name is non-empty, only
+// Secrets matching them are returned. SOPS-encrypted files are decrypted
+// with the identity from --age-identity or the SOPS_AGE_* environment.
+func loadSecrets(cmd *cobra.Command, flag, pattern, name string) ([]sourcedSecret, error) {
+	files, err := expandInputs(flag, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := ""
+	if cmd.Root().PersistentFlags().Changed("namespace") {
+		namespace, _ = cmd.Root().PersistentFlags().GetString("namespace")
+	}
+
+	var out []sourcedSecret
+	for _, f := range files {
+		data, _, err := readManifest(cmd, f)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := manifest.ParseStream(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		for _, s := range manifest.Secrets(docs) {
+			if name != "" && s.Name != name {
+				continue
+			}
+			if namespace != "" && s.Namespace != namespace {
+				continue
+			}
+			out = append(out, sourcedSecret{Path: f, Secret: s})
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s: no matching Secret found in %q", flag, pattern)
+	}
+	return out, nil
+}
+
+// addSelectFlag registers --name on a command that changes one Secret, to
+// pick it out of a file holding several.
+func addSelectFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("name", "N", "",
+		"Name of the Secret to change when --input holds several Secrets (with --namespace if names repeat)")
+}
+
+// selectSecret parses the Secret a command changes. A single document is
+// parsed as before; in a stream of documents exactly one Secret must match
+// --name and, when set explicitly, the global --namespace. Writing it back
+// with writeSecretTo replaces only that Secret.
+func selectSecret(cmd *cobra.Command, data []byte) (*corev1.Secret, error) {
+	if len(manifest.SplitDocuments(data)) <= 1 {
+		return manifest.FromYAML(data)
+	}
+	docs, err := manifest.ParseStream(data)
+	if err != nil {
+		return nil, err
+	}
+	name := ""
+	if f := cmd.Flags().Lookup("name"); f != nil {
+		name = f.Value.String()
+	}
+	namespace := ""
+	if cmd.Root().PersistentFlags().Changed("namespace") {
+		namespace, _ = cmd.Root().PersistentFlags().GetString("namespace")
+	}
+	return manifest.Select(docs, name, namespace)
+}
+
+// loadSecretToChange reads the file at path and selects the Secret to change
+// (see selectSecret).
+func loadSecretToChange(cmd *cobra.Command, path string) (*corev1.Secret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file %q: %w", path, err)
+	}
+	return selectSecret(cmd, data)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
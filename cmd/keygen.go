@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/keygen"
+	"github.com/pbsladek/k8s-secret-manifest/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+// keygenTarget is one --keygen request: the key to fill, what to generate,
+// and optionally the key that receives the public half.
+type keygenTarget struct {
+	key    string
+	spec   keygen.Spec
+	public string
+}
+
+// addKeygenFlags registers --keygen and --public-key.
+func addKeygenFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("keygen", nil,
+		"KEY=SPEC: fill KEY with generated key material; repeatable\n"+
+			"(SPEC: rsa[:BITS], ecdsa[:p256|p384|p521], ed25519, ssh-rsa[:BITS], ssh-ecdsa[:CURVE], ssh-ed25519, bytes:N[:base64|hex|raw])")
+	cmd.Flags().StringArray("public-key", nil,
+		"PRIVATE=PUBLIC: also write the public half of the --keygen key PRIVATE to PUBLIC; repeatable")
+}
+
+// keygenTargets parses --keygen and --public-key.
+func keygenTargets(cmd *cobra.Command) ([]keygenTarget, error) {
+	specs, _ := cmd.Flags().GetStringArray("keygen")
+	publics, _ := cmd.Flags().GetStringArray("public-key")
+
+	var targets []keygenTarget
+	index := make(map[string]int, len(specs))
+	for _, kv := range specs {
+		k, v, err := splitKeyValue(kv)
+		if err != nil {
+			return nil, fmt.Errorf("--keygen: %w", err)
+		}
+		if err := validate.ValidateDataKey(k); err != nil {
+			return nil, fmt.Errorf("--keygen: %w", err)
+		}
+		if _, dup := index[k]; dup {
+			return nil, fmt.Errorf("--keygen: key %q given more than once", k)
+		}
+		spec, err := keygen.ParseSpec(v)
+		if err != nil {
+			return nil, fmt.Errorf("--keygen %s: %w", k, err)
+		}
+		index[k] = len(targets)
+		targets = append(targets, keygenTarget{key: k, spec: spec})
+	}
+
+	for _, kv := range publics {
+		priv, pub, err := splitKeyValue(kv)
+		if err != nil {
+			return nil, fmt.Errorf("--public-key: %w", err)
+		}
+		if err := validate.ValidateDataKey(pub); err != nil {
+			return nil, fmt.Errorf("--public-key: %w", err)
+		}
+		i, ok := index[priv]
+		if !ok {
+			return nil, fmt.Errorf("--public-key %s: %q is not a --keygen key", kv, priv)
+		}
+		if !targets[i].spec.HasPublic() {
+			return nil, fmt.Errorf("--public-key %s: %s has no public key", kv, targets[i].spec)
+		}
+		if _, clash := index[pub]; clash || pub == priv {
+			return nil, fmt.Errorf("--public-key %s: %q is also a --keygen key", kv, pub)
+		}
+		targets[i].public = pub
+	}
+	return targets, nil
+}
+
+// applyKeygen generates key material for every target and stores it in s.
+// With setType, an OpenSSH key written to ssh-privatekey makes s a
+// kubernetes.io/ssh-auth Secret.
+func applyKeygen(s *corev1.Secret, targets []keygenTarget, setType bool) error {
+	for _, t := range targets {
+		m, err := t.spec.Generate()
+		if err != nil {
+			return fmt.Errorf("generate %s for %q: %w", t.spec, t.key, err)
+		}
+		s.Data[t.key] = m.Private
+		if t.public != "" {
+			s.Data[t.public] = m.Public
+		}
+		if t.key == corev1.SSHAuthPrivateKey && t.spec.Format == keygen.FormatOpenSSH && setType {
+			s.Type = corev1.SecretTypeSSHAuth
+		}
+		fmt.Fprintf(os.Stderr, "Generated %s for %s\n", t.spec, t.key)
+	}
+	return nil
+}
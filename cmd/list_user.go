@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/entrylist"
+	"github.com/pbsladek/k8s-secret-manifest/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var listEntriesCmd = &cobra.Command{
+	Use:   "list-entries",
+	Short: "List the entries of a paired index-list secret",
+	Long: `Print the entries of a secret that stores two parallel semicolon-separated
+lists in two data keys, matched by index position: one row per index with the
+identifier and its value.
+
+Values are masked by default (length and a short SHA-256 fingerprint). Pass
+--reveal to print every value, or --reveal-entry ID to reveal selected entries.
+
+The lists are shown as stored, and inconsistencies are reported: lists of
+different lengths, duplicate identifiers, and empty keys or values, which the
+other entry commands drop when they parse the lists and so shift the pairing
+of every later entry. The command exits non-zero when there are problems.
+
+Example:
+  k8s-secret-manifest list-entries \
+    --input secret.yaml \
+    --entries-key  BACKEND_USERS \
+    --entries-val  BACKEND_PASSWORDS`,
+	RunE: runListEntries,
+}
+
+func init() {
+	listEntriesCmd.Flags().StringP("input", "i", "", "Input secret manifest file, directory, or glob (required)")
+	_ = listEntriesCmd.MarkFlagRequired("input")
+	listEntriesCmd.Flags().StringP("name", "N", "", "Only include Secrets with this name")
+
+	listEntriesCmd.Flags().StringP("entries-key", "K", "",
+		"Data key name holding the semicolon-separated identifier list (required)")
+	_ = listEntriesCmd.MarkFlagRequired("entries-key")
+
+	listEntriesCmd.Flags().StringP("entries-val", "V", "",
+		"Data key name holding the semicolon-separated value list (required)")
+	_ = listEntriesCmd.MarkFlagRequired("entries-val")
+
+	listEntriesCmd.Flags().StringP("separator", "S", ";", "Separator used in the list values")
+	addQuoteFlag(listEntriesCmd)
+	addRevealEntryFlags(listEntriesCmd)
+	addAgeIdentityFlag(listEntriesCmd)
+}
+
+func runListEntries(cmd *cobra.Command, _ []string) error {
+	inputPath, _ := cmd.Flags().GetString("input")
+	name, _ := cmd.Flags().GetString("name")
+	entriesKey, _ := cmd.Flags().GetString("entries-key")
+	entriesVal, _ := cmd.Flags().GetString("entries-val")
+
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	codec, err := entryCodec(cmd)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := loadSecrets(cmd, "--input", inputPath, name)
+	if err != nil {
+		return fmt.Errorf("load secret: %w", err)
+	}
+	if len(secrets) > 1 {
+		return fmt.Errorf("list-entries requires a single Secret, but %d were found; select one with --name or --namespace",
+			len(secrets))
+	}
+	src := secrets[0]
+	s := src.Secret
+	for _, k := range []string{entriesKey, entriesVal} {
+		if _, ok := s.Data[k]; !ok {
+			return fmt.Errorf("key %q not found in secret data", k)
+		}
+	}
+
+	// Not loadTable: ParseTable drops empty items and rejects mismatched or
+	// duplicate lists, which are exactly what this command has to show.
+	rows, problems, err := codec.Inspect(string(s.Data[entriesKey]), string(s.Data[entriesVal]))
+	if err != nil {
+		return err
+	}
+	r := newRedactor(cmd)
+
+	doc := report.EntryList{
+		Source:    sourceOf(src),
+		KeysKey:   entriesKey,
+		ValuesKey: entriesVal,
+		Entries:   make([]report.ListEntry, 0, len(rows)),
+		Problems:  make([]report.EntryProblem, 0, len(problems)),
+	}
+	for _, row := range rows {
+		e := report.ListEntry{Index: row.Index, Key: row.Key, Value: r.reportValue(row.Key, []byte(row.Value))}
+		switch {
+		case !row.HasKey:
+			e.Missing = "key"
+		case !row.HasValue:
+			e.Missing = "value"
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+	for _, p := range problems {
+		doc.Problems = append(doc.Problems, report.EntryProblem(p))
+	}
+
+	if format != formatText {
+		if err := printStructured(format, doc); err != nil {
+			return err
+		}
+	} else {
+		printEntryList(doc, r, rows)
+	}
+
+	if len(doc.Problems) > 0 {
+		return fmt.Errorf("%d problem(s) in %s/%s", len(doc.Problems), entriesKey, entriesVal)
+	}
+	return nil
+}
+
+// printEntryList prints doc as a table, with problems on stderr.
+func printEntryList(doc report.EntryList, r *redactor, rows []entrylist.Row) {
+	fmt.Printf("Secret: %s/%s  %s / %s  (%d entr(ies))\n",
+		doc.Namespace, doc.Name, doc.KeysKey, doc.ValuesKey, len(rows))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  INDEX\tKEY\tVALUE\n")
+	for _, row := range rows {
+		key, val := row.Key, r.value(row.Key, []byte(row.Value))
+		if !row.HasKey {
+			key = "<missing>"
+		}
+		if !row.HasValue {
+			val = "<missing>"
+		}
+		fmt.Fprintf(w, "  %d\t%s\t%s\n", row.Index, key, val)
+	}
+	_ = w.Flush()
+
+	for _, p := range doc.Problems {
+		fmt.Fprintf(os.Stderr, "problem: %s\n", p.Message)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/password"
+	"github.com/spf13/cobra"
+)
+
+const maxPassphraseWords = 64
+
+// characterFlags are the password flags that do not apply to --words.
+var characterFlags = []string{
+	"length", "charset", "custom-charset", "exclude-chars",
+	"min-lower", "min-upper", "min-digits", "min-symbols",
+}
+
+// addPasswordFlags registers the flags that describe how random values are
+// generated. lengthShort and charsetShort are the shorthands for --length and
+// --charset, or "" for none.
+func addPasswordFlags(cmd *cobra.Command, lengthShort, charsetShort string) {
+	cmd.Flags().IntP("length", lengthShort, 32,
+		"Length of the generated value in characters (max 4096)")
+	cmd.Flags().StringP("charset", charsetShort, "alphanumeric",
+		"Character set for generated value: alphanumeric, hex, base64url, printable")
+	cmd.Flags().String("custom-charset", "",
+		"Generate from exactly these characters instead of --charset (printable ASCII)")
+	cmd.Flags().String("exclude-chars", "",
+		"Characters never to generate (e.g. 0O1lI)")
+	cmd.Flags().Int("min-lower", 0, "Minimum number of lowercase letters")
+	cmd.Flags().Int("min-upper", 0, "Minimum number of uppercase letters")
+	cmd.Flags().Int("min-digits", 0, "Minimum number of digits")
+	cmd.Flags().Int("min-symbols", 0, "Minimum number of symbols (use --charset printable or --custom-charset)")
+	cmd.Flags().Int("words", 0,
+		"Generate a diceware passphrase of this many words from the bundled EFF wordlist instead")
+	cmd.Flags().String("word-separator", "-", "Separator between passphrase words")
+}
+
+// passwordGenerator builds the generator described by the flags registered
+// with addPasswordFlags, rejecting policies that cannot be satisfied.
+func passwordGenerator(cmd *cobra.Command) (password.Generator, error) {
+	words, _ := cmd.Flags().GetInt("words")
+	if cmd.Flags().Changed("words") {
+		for _, name := range characterFlags {
+			if cmd.Flags().Changed(name) {
+				return nil, fmt.Errorf("--words cannot be combined with --%s", name)
+			}
+		}
+		if words > maxPassphraseWords {
+			return nil, fmt.Errorf("--words %d exceeds maximum of %d", words, maxPassphraseWords)
+		}
+		sep, _ := cmd.Flags().GetString("word-separator")
+		p := password.Passphrase{Words: words, Separator: sep}
+		if err := p.Check(); err != nil {
+			return nil, fmt.Errorf("passphrase: %w", err)
+		}
+		return p, nil
+	}
+
+	length, _ := cmd.Flags().GetInt("length")
+	charsetName, _ := cmd.Flags().GetString("charset")
+	custom, _ := cmd.Flags().GetString("custom-charset")
+	exclude, _ := cmd.Flags().GetString("exclude-chars")
+
+	if length > maxRotateLength {
+		return nil, fmt.Errorf("--length %d exceeds maximum of %d", length, maxRotateLength)
+	}
+
+	charset := custom
+	if custom == "" {
+		var err error
+		if charset, err = resolveCharset(charsetName); err != nil {
+			return nil, err
+		}
+	} else if cmd.Flags().Changed("charset") {
+		return nil, fmt.Errorf("--custom-charset cannot be combined with --charset")
+	}
+	alphabet, err := password.Alphabet(charset, exclude)
+	if err != nil {
+		return nil, fmt.Errorf("charset: %w", err)
+	}
+
+	p := password.Policy{Length: length, Alphabet: alphabet}
+	p.MinLower, _ = cmd.Flags().GetInt("min-lower")
+	p.MinUpper, _ = cmd.Flags().GetInt("min-upper")
+	p.MinDigits, _ = cmd.Flags().GetInt("min-digits")
+	p.MinSymbols, _ = cmd.Flags().GetInt("min-symbols")
+	if err := p.Check(); err != nil {
+		return nil, fmt.Errorf("password policy: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+// addPolicyFlag registers --policy on commands that evaluate policy rules.
+func addPolicyFlag(cmd *cobra.Command) {
+	cmd.Flags().String("policy", "", "Policy file (YAML) of additional rules to enforce")
+}
+
+// loadPolicy loads the --policy file, returning nil when none is given.
+func loadPolicy(cmd *cobra.Command) (*policy.Policy, error) {
+	path, _ := cmd.Flags().GetString("policy")
+	if path == "" {
+		return nil, nil
+	}
+	safe, err := safePath("--policy", path)
+	if err != nil {
+		return nil, err
+	}
+	return policy.Load(safe)
+}
+
+// enforcePolicy checks s against p before a write. Warnings are printed to
+// stderr; error-severity violations are returned as a single error so that
+// nothing is written. A nil policy allows everything.
+func enforcePolicy(p *policy.Policy, s *corev1.Secret) error {
+	if p == nil {
+		return nil
+	}
+	var errs []string
+	for _, issue := range p.Check(s) {
+		if issue.IsError() {
+			errs = append(errs, issue.Message)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "warning: %s\n", issue.Message)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("secret %s/%s violates policy: %s", s.Namespace, s.Name, strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// fingerprintLen is the number of hex digits of the SHA-256 shown in masked
+// output: enough to tell values apart, too short to be useful as a hash.
+const fingerprintLen = 8
+
+// redactor decides which decoded values may be printed in clear text.
+// Everything is masked unless --reveal, --reveal-key, or --reveal-entry says
+// otherwise.
+type redactor struct {
+	revealAll bool
+	keys      map[string]bool
+}
+
+// addRevealFlags registers --reveal and --reveal-key on cmd.
+func addRevealFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("reveal", false, "Print all values in clear text instead of masked")
+	cmd.Flags().StringArray("reveal-key", nil,
+		"Print this key's value in clear text; repeatable (e.g. --reveal-key API_KEY)")
+}
+
+// addRevealEntryFlags registers --reveal and --reveal-entry on a command
+// that lists paired-list entries, where values are looked up by entry
+// identifier rather than by data key.
+func addRevealEntryFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("reveal", false, "Print all values in clear text instead of masked")
+	cmd.Flags().StringArray("reveal-entry", nil,
+		"Print the value of the entry with this identifier in clear text; repeatable (e.g. --reveal-entry alice)")
+}
+
+func newRedactor(cmd *cobra.Command) *redactor {
+	revealAll, _ := cmd.Flags().GetBool("reveal")
+	revealKeys, _ := cmd.Flags().GetStringArray("reveal-key")
+	if cmd.Flags().Lookup("reveal-entry") != nil {
+		revealKeys, _ = cmd.Flags().GetStringArray("reveal-entry")
+	}
+
+	r := &redactor{revealAll: revealAll, keys: make(map[string]bool, len(revealKeys))}
+	for _, k := range revealKeys {
+		r.keys[k] = true
+	}
+	return r
+}
+
+// value returns val for display under key: in clear text when revealed,
+// otherwise masked.
+func (r *redactor) value(key string, val []byte) string {
+	if r.revealAll || r.keys[key] {
+		return string(val)
+	}
+	return maskValue(val)
+}
+
+// reportValue is value for structured output: size and fingerprint always,
+// the plain value only when revealed.
+func (r *redactor) reportValue(key string, val []byte) report.Value {
+	v := report.Value{Size: len(val), Fingerprint: fingerprint(val)}
+	if r.revealAll || r.keys[key] {
+		plain := string(val)
+		v.Plain = &plain
+	}
+	return v
+}
+
+// maskValue describes val by its length and a short SHA-256 fingerprint, so
+// equal values can be recognised without printing them.
+func maskValue(val []byte) string {
+	return fmt.Sprintf("<masked len=%d sha256:%s>", len(val), fingerprint(val))
+}
+
+func fingerprint(val []byte) string {
+	sum := sha256.Sum256(val)
+	return hex.EncodeToString(sum[:])[:fingerprintLen]
+}
@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/pbsladek/k8s-secret-manifest/internal/entrylist"
-	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +14,8 @@ var removeEntryCmd = &cobra.Command{
 semicolon-separated lists in two data keys, matched by index position.
 
 Specify the entry to remove by its key OR by its value — not both.
+For tables of three or more parallel lists, repeat --entries-val for every
+value column so the whole row is removed; --value matches the first one.
 
 Remove by key (removes alice and its paired value):
   k8s-secret-manifest remove-entry \
@@ -39,29 +39,33 @@ func init() {
 
 	removeEntryCmd.Flags().StringP("output", "o", "",
 		"Output file path (default: same as --input)")
+	removeEntryCmd.Flags().Bool("backup", false,
+		"Keep the previous version of the output file as <output>.bak")
+	addSelectFlag(removeEntryCmd)
 
 	removeEntryCmd.Flags().StringP("entries-key", "K", "",
 		"Data key name holding the semicolon-separated identifier list (required)")
 	_ = removeEntryCmd.MarkFlagRequired("entries-key")
 
-	removeEntryCmd.Flags().StringP("entries-val", "V", "",
-		"Data key name holding the semicolon-separated value list (required)")
+	removeEntryCmd.Flags().StringArrayP("entries-val", "V", nil,
+		"Data key name holding a semicolon-separated value list; repeat for each value column (required)")
 	_ = removeEntryCmd.MarkFlagRequired("entries-val")
 
 	removeEntryCmd.Flags().StringP("key", "k", "", "Remove the entry with this key (mutually exclusive with --value)")
 	removeEntryCmd.Flags().StringP("value", "v", "", "Remove the entry with this value (mutually exclusive with --key)")
 
 	removeEntryCmd.Flags().StringP("separator", "S", ";", "Separator used in the list values")
+	addQuoteFlag(removeEntryCmd)
 }
 
 func runRemoveEntry(cmd *cobra.Command, _ []string) error {
 	inputPath, _ := cmd.Flags().GetString("input")
 	outputPath, _ := cmd.Flags().GetString("output")
+	backup, _ := cmd.Flags().GetBool("backup")
 	entriesKey, _ := cmd.Flags().GetString("entries-key")
-	entriesVal, _ := cmd.Flags().GetString("entries-val")
+	entriesVals, _ := cmd.Flags().GetStringArray("entries-val")
 	key, _ := cmd.Flags().GetString("key")
 	value, _ := cmd.Flags().GetString("value")
-	sep, _ := cmd.Flags().GetString("separator")
 
 	if key == "" && value == "" {
 		return fmt.Errorf("one of --key or --value is required")
@@ -70,6 +74,11 @@ func runRemoveEntry(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("--key and --value are mutually exclusive")
 	}
 
+	codec, err := entryCodec(cmd)
+	if err != nil {
+		return err
+	}
+
 	if outputPath == "" {
 		outputPath = inputPath
 	}
@@ -80,29 +89,40 @@ func runRemoveEntry(cmd *cobra.Command, _ []string) error {
 	}
 
 	return withExclusiveLock(outputPath, func() error {
-		s, err := manifest.FromFile(safeInput)
+		s, err := loadSecretToChange(cmd, safeInput)
 		if err != nil {
 			return fmt.Errorf("load secret: %w", err)
 		}
 
-		entries, err := loadEntries(s, entriesKey, entriesVal, sep)
+		table, err := loadTable(s, entriesKey, entriesVals, codec)
 		if err != nil {
 			return err
 		}
 
 		var removed string
 		if key != "" {
-			entries, err = entrylist.Remove(entries, key)
+			table, err = table.Remove(key)
 			removed = key
 		} else {
-			entries, err = entrylist.RemoveByValue(entries, value)
+			table, err = table.RemoveWhere(1, value)
 			removed = value
 		}
 		if err != nil {
 			return err
 		}
 
-		storeEntries(s, entriesKey, entriesVal, sep, entries)
+		if err := storeTable(s, codec, table); err != nil {
+			return err
+		}
+
+		if err := checkLimits(s); err != nil {
+			return err
+		}
+		if backup {
+			if err := backupExisting(outputPath); err != nil {
+				return err
+			}
+		}
 
 		if err := writeSecretTo(outputPath, s); err != nil {
 			return err
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var renameEntryCmd = &cobra.Command{
+	Use:   "rename-entry",
+	Short: "Rename an entry in a paired index-list secret",
+	Long: `Change the identifier of an entry in a secret that stores two parallel
+semicolon-separated lists in two data keys, matched by index position.
+
+The entry keeps its values and its position in every list. For tables of
+three or more parallel lists, repeat --entries-val for every value column.
+
+  k8s-secret-manifest rename-entry \
+    --input secret.yaml \
+    --entries-key  BACKEND_USERS \
+    --entries-val  BACKEND_PASSWORDS \
+    --key bob \
+    --new-key robert`,
+	RunE: runRenameEntry,
+}
+
+func init() {
+	renameEntryCmd.Flags().StringP("input", "i", "", "Input secret manifest file (required)")
+	_ = renameEntryCmd.MarkFlagRequired("input")
+
+	renameEntryCmd.Flags().StringP("output", "o", "",
+		"Output file path (default: same as --input)")
+	renameEntryCmd.Flags().Bool("backup", false,
+		"Keep the previous version of the output file as <output>.bak")
+	addSelectFlag(renameEntryCmd)
+
+	renameEntryCmd.Flags().StringP("entries-key", "K", "",
+		"Data key name holding the semicolon-separated identifier list (required)")
+	_ = renameEntryCmd.MarkFlagRequired("entries-key")
+
+	renameEntryCmd.Flags().StringArrayP("entries-val", "V", nil,
+		"Data key name holding a semicolon-separated value list; repeat for each value column (required)")
+	_ = renameEntryCmd.MarkFlagRequired("entries-val")
+
+	renameEntryCmd.Flags().StringP("key", "k", "", "Current identifier of the entry (required)")
+	_ = renameEntryCmd.MarkFlagRequired("key")
+
+	renameEntryCmd.Flags().String("new-key", "", "New identifier for the entry (required)")
+	_ = renameEntryCmd.MarkFlagRequired("new-key")
+
+	renameEntryCmd.Flags().StringP("separator", "S", ";", "Separator used in the list values")
+	addQuoteFlag(renameEntryCmd)
+}
+
+func runRenameEntry(cmd *cobra.Command, _ []string) error {
+	inputPath, _ := cmd.Flags().GetString("input")
+	outputPath, _ := cmd.Flags().GetString("output")
+	backup, _ := cmd.Flags().GetBool("backup")
+	entriesKey, _ := cmd.Flags().GetString("entries-key")
+	entriesVals, _ := cmd.Flags().GetStringArray("entries-val")
+	key, _ := cmd.Flags().GetString("key")
+	newKey, _ := cmd.Flags().GetString("new-key")
+
+	codec, err := entryCodec(cmd)
+	if err != nil {
+		return err
+	}
+
+	if outputPath == "" {
+		outputPath = inputPath
+	}
+
+	safeInput, err := safePath("--input", inputPath)
+	if err != nil {
+		return err
+	}
+
+	return withExclusiveLock(outputPath, func() error {
+		s, err := loadSecretToChange(cmd, safeInput)
+		if err != nil {
+			return fmt.Errorf("load secret: %w", err)
+		}
+
+		table, err := loadTable(s, entriesKey, entriesVals, codec)
+		if err != nil {
+			return err
+		}
+
+		table, err = table.Rename(key, newKey)
+		if err != nil {
+			return err
+		}
+
+		if err := storeTable(s, codec, table); err != nil {
+			return err
+		}
+
+		if err := checkLimits(s); err != nil {
+			return err
+		}
+		if backup {
+			if err := backupExisting(outputPath); err != nil {
+				return err
+			}
+		}
+
+		if err := writeSecretTo(outputPath, s); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Renamed entry %q to %q in %s\n", key, newKey, outputPath)
+		return nil
+	})
+}
@@ -14,7 +14,8 @@ var rootCmd = &cobra.Command{
 	Short: "Generate and seal Kubernetes Secret manifests",
 	Long: `k8s-secret-manifest generates valid Kubernetes Secret YAML manifests,
 handles base64 encoding of plain-text values, manages paired index-list keys,
-and seals secrets using the kubeseal CLI.`,
+and seals secrets into SealedSecrets (natively with a public certificate, or
+via the kubeseal CLI).`,
 }
 
 // Execute runs the root command.
@@ -24,7 +25,9 @@ func Execute() error {
 
 func init() {
 	rootCmd.PersistentFlags().StringP("namespace", "n", "default", "Kubernetes namespace")
-	rootCmd.PersistentFlags().StringP("kubeseal-path", "p", "kubeseal", "Path to kubeseal binary")
+	rootCmd.PersistentFlags().String("output-format", formatText,
+		"Output format for list, show, diff, validate, stale, and list-entries: text, json, or yaml")
+	rootCmd.PersistentFlags().StringP("kubeseal-path", "p", "kubeseal", "Path to kubeseal binary (used for online sealing, or to force kubeseal with --cert)")
 
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(fromEnvCmd)
@@ -36,13 +39,22 @@ func init() {
 	rootCmd.AddCommand(showCmd)
 	rootCmd.AddCommand(diffCmd)
 	rootCmd.AddCommand(sealCmd)
+	rootCmd.AddCommand(unsealCmd)
+	rootCmd.AddCommand(encryptCmd)
+	rootCmd.AddCommand(decryptCmd)
 	rootCmd.AddCommand(addEntryCmd)
 	rootCmd.AddCommand(removeEntryCmd)
+	rootCmd.AddCommand(updateEntryCmd)
+	rootCmd.AddCommand(renameEntryCmd)
+	rootCmd.AddCommand(moveEntryCmd)
+	rootCmd.AddCommand(listEntriesCmd)
 	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(staleCmd)
 	rootCmd.AddCommand(editCmd)
 }
 
 // writeOutput writes data to a file or stdout.
+// Files are replaced atomically (see writeFileAtomic).
 func writeOutput(path string, data []byte) error {
 	if path == "" {
 		_, err := os.Stdout.Write(data)
@@ -52,7 +64,87 @@ func writeOutput(path string, data []byte) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(safe, data, 0600)
+	return writeFileAtomic(safe, data)
+}
+
+// writeFileAtomic writes data to a temp file in the same directory as path,
+// fsyncs it, and renames it over path, so a crash or full disk never leaves a
+// truncated file behind. If path already exists its mode and (where the
+// platform allows) ownership are preserved; new files are created 0600.
+// A symlink at path is followed and its target replaced.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0600)
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		mode = info.Mode().Perm()
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("stat %q: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file for %q: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("write %q: %w", tmpPath, err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		return fmt.Errorf("chmod %q: %w", tmpPath, err)
+	}
+	if info != nil {
+		preserveOwner(tmp, info)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("sync %q: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %q: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replace %q: %w", path, err)
+	}
+	committed = true
+	syncDir(dir)
+	return nil
+}
+
+// backupExisting copies the current contents of path to path+".bak" before an
+// in-place rewrite. A missing path is not an error: there is nothing to keep.
+// Callers run checkLimits first, so a write that will be rejected does not
+// replace the previous backup.
+func backupExisting(path string) error {
+	if path == "" {
+		return nil
+	}
+	safe, err := safePath("--output", path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(safe)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("backup: read %q: %w", safe, err)
+	}
+	if err := writeFileAtomic(safe+".bak", data); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	return nil
 }
 
 // splitKeyValue parses "key=value", allowing "=" in the value portion.
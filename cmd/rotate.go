@@ -1,13 +1,16 @@
 package cmd
 
 import (
-	"crypto/rand"
 	"fmt"
-	"math/big"
 	"os"
+	"slices"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/entrylist"
 	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	"github.com/pbsladek/k8s-secret-manifest/internal/password"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +18,7 @@ const (
 	charsetAlphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	charsetHex          = "0123456789abcdef"
 	charsetBase64URL    = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+	charsetPrintable    = charsetAlphanumeric + password.Symbols
 
 	maxRotateLength = 4096
 )
@@ -24,8 +28,16 @@ var rotateCmd = &cobra.Command{
 	Short: "Rotate keys with new cryptographically random values",
 	Long: `Replace one or more data keys with new cryptographically random values.
 
-The new plain-text values are printed to stderr so they can be recorded.
-The secret file is updated in place (or to --output if specified).
+The new plain-text values are printed to stderr so they can be recorded,
+followed by the entropy of each value in bits. The secret file is updated in
+place (or to --output if specified).
+
+Values can be shaped by a password policy: --min-lower, --min-upper,
+--min-digits, and --min-symbols require characters of each class,
+--custom-charset replaces the character set, and --exclude-chars removes
+ambiguous or shell-hostile characters. --words generates a diceware
+passphrase from the bundled EFF large wordlist instead. Every generated value
+is checked against the policy before it is written.
 
 Example — rotate a single key:
   k8s-secret-manifest rotate --input secret.yaml --key API_KEY
@@ -33,7 +45,46 @@ Example — rotate a single key:
 Example — rotate multiple keys with a hex value of length 64:
   k8s-secret-manifest rotate --input secret.yaml \
     --key DB_PASS --key JWT_SECRET \
-    --length 64 --charset hex`,
+    --length 64 --charset hex
+
+Example — database password with every character class and no ambiguous characters:
+  k8s-secret-manifest rotate --input secret.yaml --key DB_PASS \
+    --charset printable --exclude-chars 0O1lI \
+    --min-lower 1 --min-upper 1 --min-digits 1 --min-symbols 1
+
+Example — six-word passphrase:
+  k8s-secret-manifest rotate --input secret.yaml --key ADMIN_PASS --words 6
+
+--keygen replaces a key with new key material instead of a random string,
+and --public-key writes its public half to another key.
+
+Example — new JWT signing key and its public key:
+  k8s-secret-manifest rotate --input secret.yaml \
+    --keygen jwt.key=rsa:3072 --public-key jwt.key=jwt.pub
+
+--reissue-tls replaces tls.crt and tls.key with a new key and certificate
+that keeps the subject, SANs, key type, and validity period (or --tls-days).
+A self-signed certificate is re-signed by its new key; otherwise the CA must
+be given with --tls-ca-secret or --tls-ca-cert/--tls-ca-key. ca.crt is
+refreshed when present.
+
+Example — reissue a CA-signed certificate:
+  k8s-secret-manifest rotate --input web-tls.yaml --reissue-tls --tls-ca-secret ca-secret.yaml
+
+--stamp records each rotation in per-key annotations (last-rotated timestamp
+and rotation count), and --max-age also sets how long the key may go without
+rotation; the stale command reports keys past their maximum age.
+
+--entries-key and --entries-val select a paired index list (see add-entry);
+--entry rotates the value paired with an identifier in place, keeping its
+position, and --all-entries rotates every value in the list.
+
+Example — new password for bob in a pgpool-style list:
+  k8s-secret-manifest rotate --input secret.yaml \
+    --entries-key BACKEND_USERS --entries-val BACKEND_PASSWORDS --entry bob
+
+Example — rotate and require the next rotation within 90 days:
+  k8s-secret-manifest rotate --input secret.yaml --key DB_PASS --max-age 90d`,
 	RunE: runRotate,
 }
 
@@ -43,36 +94,88 @@ func init() {
 
 	rotateCmd.Flags().StringP("output", "o", "",
 		"Output file path (default: same as --input)")
+	rotateCmd.Flags().Bool("backup", false,
+		"Keep the previous version of the output file as <output>.bak")
+	addSelectFlag(rotateCmd)
 
 	rotateCmd.Flags().StringArrayP("key", "k", nil,
-		"Key to rotate; repeatable (required)")
-	_ = rotateCmd.MarkFlagRequired("key")
+		"Key to rotate with a random value; repeatable")
+
+	rotateCmd.Flags().StringP("entries-key", "K", "",
+		"Data key holding the identifier list of a paired index list")
+	rotateCmd.Flags().StringP("entries-val", "V", "",
+		"Data key holding the value list of a paired index list")
+	rotateCmd.Flags().StringArrayP("entry", "e", nil,
+		"Identifier whose paired value to rotate in place; repeatable")
+	rotateCmd.Flags().Bool("all-entries", false, "Rotate the value of every entry in the paired lists")
+	rotateCmd.Flags().StringP("separator", "S", ";", "Separator used in the list values")
+	addQuoteFlag(rotateCmd)
 
-	rotateCmd.Flags().IntP("length", "l", 32,
-		"Length of the generated value in characters (max 4096)")
-	rotateCmd.Flags().StringP("charset", "c", "alphanumeric",
-		"Character set for generated value: alphanumeric, hex, base64url")
+	addPasswordFlags(rotateCmd, "l", "c")
+	addKeygenFlags(rotateCmd)
+	rotateCmd.Flags().Bool("reissue-tls", false,
+		"Replace tls.crt and tls.key with a new key and certificate for the same subject and SANs")
+	rotateCmd.Flags().Int("tls-days", 0,
+		"Validity of the reissued certificate in days (default: same as the current certificate)")
+	addTLSCAFlags(rotateCmd)
+	addStampFlags(rotateCmd)
+	addAgeIdentityFlag(rotateCmd)
 }
 
 func runRotate(cmd *cobra.Command, _ []string) error {
 	inputPath, _ := cmd.Flags().GetString("input")
 	outputPath, _ := cmd.Flags().GetString("output")
+	backup, _ := cmd.Flags().GetBool("backup")
 	keys, _ := cmd.Flags().GetStringArray("key")
-	length, _ := cmd.Flags().GetInt("length")
-	charsetName, _ := cmd.Flags().GetString("charset")
+	reissue, _ := cmd.Flags().GetBool("reissue-tls")
+	entriesKey, _ := cmd.Flags().GetString("entries-key")
+	entriesVal, _ := cmd.Flags().GetString("entries-val")
+	entryIDs, _ := cmd.Flags().GetStringArray("entry")
+	allEntries, _ := cmd.Flags().GetBool("all-entries")
 
 	if outputPath == "" {
 		outputPath = inputPath
 	}
 
-	if length > maxRotateLength {
-		return fmt.Errorf("--length %d exceeds maximum of %d", length, maxRotateLength)
+	gen, err := passwordGenerator(cmd)
+	if err != nil {
+		return err
 	}
-
-	charset, err := resolveCharset(charsetName)
+	targets, err := keygenTargets(cmd)
 	if err != nil {
 		return err
 	}
+	st, err := stampFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	rotateEntries := len(entryIDs) > 0 || allEntries
+	if len(keys) == 0 && len(targets) == 0 && !reissue && !rotateEntries {
+		return fmt.Errorf("at least one --key, --keygen, --entry, --all-entries, or --reissue-tls is required")
+	}
+	for _, t := range targets {
+		if slices.Contains(keys, t.key) {
+			return fmt.Errorf("key %q given to both --key and --keygen", t.key)
+		}
+	}
+	codec, err := entryCodec(cmd)
+	if err != nil {
+		return err
+	}
+	if rotateEntries || entriesKey != "" || entriesVal != "" {
+		if entriesKey == "" || entriesVal == "" {
+			return fmt.Errorf("--entries-key and --entries-val are both required when rotating entries")
+		}
+		if !rotateEntries {
+			return fmt.Errorf("--entry or --all-entries is required with --entries-key/--entries-val")
+		}
+		if len(entryIDs) > 0 && allEntries {
+			return fmt.Errorf("--entry and --all-entries are mutually exclusive")
+		}
+		if slices.Contains(keys, entriesKey) || slices.Contains(keys, entriesVal) {
+			return fmt.Errorf("the paired list keys cannot also be rotated with --key")
+		}
+	}
 
 	safeInput, err := safePath("--input", inputPath)
 	if err != nil {
@@ -80,7 +183,7 @@ func runRotate(cmd *cobra.Command, _ []string) error {
 	}
 
 	return withExclusiveLock(outputPath, func() error {
-		s, err := manifest.FromFile(safeInput)
+		s, key, err := loadSecretFile(cmd, safeInput)
 		if err != nil {
 			return fmt.Errorf("load secret: %w", err)
 		}
@@ -89,23 +192,133 @@ func runRotate(cmd *cobra.Command, _ []string) error {
 			if _, ok := s.Data[key]; !ok {
 				return fmt.Errorf("key %q not found in secret data", key)
 			}
-			val, err := randomString(length, charset)
+			val, err := gen.Generate()
 			if err != nil {
 				return fmt.Errorf("generate value for %q: %w", key, err)
 			}
 			manifest.SetPlainValue(s, key, val)
 			fmt.Fprintf(os.Stderr, "%s=%s\n", key, val)
+			if err := st.stamp(s, key); err != nil {
+				return err
+			}
+		}
+
+		for _, t := range targets {
+			if _, ok := s.Data[t.key]; !ok {
+				return fmt.Errorf("key %q not found in secret data", t.key)
+			}
+		}
+		rotated := 0
+		if rotateEntries {
+			if rotated, err = rotateListEntries(s, entriesKey, entriesVal, codec, entryIDs, gen); err != nil {
+				return err
+			}
+			if err := st.stamp(s, entriesVal); err != nil {
+				return err
+			}
 		}
 
-		if err := writeSecretTo(outputPath, s); err != nil {
+		if err := applyKeygen(s, targets, false); err != nil {
+			return err
+		}
+		for _, t := range targets {
+			if err := st.stamp(s, t.key); err != nil {
+				return err
+			}
+			if t.public != "" {
+				if err := st.stamp(s, t.public); err != nil {
+					return err
+				}
+			}
+		}
+
+		if reissue {
+			if err := reissueTLS(cmd, s); err != nil {
+				return err
+			}
+			if err := st.stamp(s, corev1.TLSCertKey, corev1.TLSPrivateKeyKey); err != nil {
+				return err
+			}
+		}
+
+		if err := checkLimits(s); err != nil {
+			return err
+		}
+		if backup {
+			if err := backupExisting(outputPath); err != nil {
+				return err
+			}
+		}
+
+		if err := writeSecretFile(outputPath, s, manifest.EmitData, key); err != nil {
 			return err
 		}
 
-		fmt.Fprintf(os.Stderr, "Rotated %d key(s) in %s\n", len(keys), outputPath)
+		if len(keys) > 0 {
+			fmt.Fprintf(os.Stderr, "Rotated %d key(s) in %s (%.0f bits of entropy each)\n",
+				len(keys), outputPath, gen.EntropyBits())
+		}
+		if rotated > 0 {
+			fmt.Fprintf(os.Stderr, "Rotated %d entr(ies) of %s in %s (%.0f bits of entropy each)\n",
+				rotated, entriesVal, outputPath, gen.EntropyBits())
+		}
+		if len(targets) > 0 {
+			fmt.Fprintf(os.Stderr, "Regenerated %d key(s) in %s\n", len(targets), outputPath)
+		}
 		return nil
 	})
 }
 
+// rotateListEntries replaces the values paired with ids in the entriesKey and
+// entriesVal lists with generated ones, keeping every entry at its index. An
+// empty ids rotates every entry. It returns the number of rotated entries.
+func rotateListEntries(s *corev1.Secret, entriesKey, entriesVal string, codec entrylist.Codec, ids []string, gen password.Generator) (int, error) {
+	if _, ok := s.Data[entriesKey]; !ok {
+		return 0, fmt.Errorf("key %q not found in secret data", entriesKey)
+	}
+	table, err := loadTable(s, entriesKey, []string{entriesVal}, codec)
+	if err != nil {
+		return 0, err
+	}
+
+	indexes := make([]int, 0, len(table.Rows))
+	if len(ids) == 0 {
+		for i := range table.Rows {
+			indexes = append(indexes, i)
+		}
+	}
+	for _, id := range ids {
+		i := table.Index(id)
+		if i < 0 {
+			return 0, fmt.Errorf("entry %q not found in %s", id, entriesKey)
+		}
+		if slices.Contains(indexes, i) {
+			return 0, fmt.Errorf("--entry %q given more than once", id)
+		}
+		indexes = append(indexes, i)
+	}
+
+	for _, i := range indexes {
+		row := table.Rows[i]
+		val, err := gen.Generate()
+		if err != nil {
+			return 0, fmt.Errorf("generate value for entry %q: %w", row[0], err)
+		}
+		// A value containing the separator would not parse back into the
+		// same entry unless it is quoted.
+		if err := codec.CheckRow(table, []string{row[0], val}); err != nil {
+			return 0, fmt.Errorf("generated %w: exclude the separator with --exclude-chars or pass --quote", err)
+		}
+		row[1] = val
+		fmt.Fprintf(os.Stderr, "%s[%s]=%s\n", entriesVal, row[0], val)
+	}
+
+	if err := storeTable(s, codec, table); err != nil {
+		return 0, err
+	}
+	return len(indexes), nil
+}
+
 // resolveCharset returns the character set string for the given name.
 func resolveCharset(name string) (string, error) {
 	switch strings.ToLower(name) {
@@ -115,8 +328,10 @@ func resolveCharset(name string) (string, error) {
 		return charsetHex, nil
 	case "base64url":
 		return charsetBase64URL, nil
+	case "printable":
+		return charsetPrintable, nil
 	default:
-		return "", fmt.Errorf("unknown charset %q: use alphanumeric, hex, or base64url", name)
+		return "", fmt.Errorf("unknown charset %q: use alphanumeric, hex, base64url, or printable", name)
 	}
 }
 
@@ -126,14 +341,5 @@ func randomString(length int, charset string) (string, error) {
 	if length <= 0 {
 		return "", fmt.Errorf("length must be positive")
 	}
-	n := big.NewInt(int64(len(charset)))
-	result := make([]byte, length)
-	for i := range result {
-		idx, err := rand.Int(rand.Reader, n)
-		if err != nil {
-			return "", err
-		}
-		result[i] = charset[idx.Int64()]
-	}
-	return string(result), nil
+	return password.Policy{Length: length, Alphabet: charset}.Generate()
 }
@@ -6,25 +6,33 @@ import (
 	"os"
 	"os/exec"
 
+	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	"github.com/pbsladek/k8s-secret-manifest/internal/sealedsecret"
 	"github.com/spf13/cobra"
 )
 
 var sealCmd = &cobra.Command{
 	Use:   "seal",
-	Short: "Seal a Secret manifest using kubeseal",
-	Long: `Seal a plain Kubernetes Secret manifest into a SealedSecret using kubeseal.
+	Short: "Seal a Secret manifest into a SealedSecret",
+	Long: `Seal a plain Kubernetes Secret manifest into a SealedSecret.
 
-The plain secret YAML is piped through kubeseal via stdin/stdout.
+With --cert, sealing is performed natively in-process using the controller's
+public certificate; the kubeseal binary is not needed. Without --cert, or when
+--kubeseal-path is set explicitly, the plain secret YAML is piped through
+kubeseal via stdin/stdout instead.
 The original plain-text file is left unchanged.
 
-Online sealing (requires cluster access):
+The scope defaults to the sealedsecrets.bitnami.com/namespace-wide or
+cluster-wide annotation on the input secret, or strict if neither is set.
+
+Online sealing via kubeseal (requires cluster access):
   k8s-secret-manifest seal \
     --input secret.yaml \
     --output sealed-secret.yaml \
     --controller-name sealed-secrets-controller \
     --controller-namespace kube-system
 
-Offline sealing (using a fetched public cert):
+Offline sealing (native, using a fetched public cert):
   k8s-secret-manifest seal \
     --input secret.yaml \
     --output sealed-secret.yaml \
@@ -43,7 +51,7 @@ func init() {
 	sealCmd.Flags().StringP("controller-namespace", "C", "kube-system",
 		"kubeseal --controller-namespace")
 	sealCmd.Flags().StringP("cert", "r", "",
-		"Path to public certificate for offline sealing (kubeseal --cert)")
+		"Path to the controller's public certificate; enables native offline sealing")
 	sealCmd.Flags().StringP("scope", "s", "",
 		"Sealing scope: strict (default), namespace-wide, or cluster-wide")
 }
@@ -56,6 +64,7 @@ func runSeal(cmd *cobra.Command, _ []string) error {
 	certPath, _ := cmd.Flags().GetString("cert")
 	scope, _ := cmd.Flags().GetString("scope")
 	kubesealPath, _ := cmd.Root().PersistentFlags().GetString("kubeseal-path")
+	forceKubeseal := cmd.Root().PersistentFlags().Changed("kubeseal-path")
 
 	safeInput, err := safePath("--input", inputPath)
 	if err != nil {
@@ -75,6 +84,14 @@ func runSeal(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("read input file %q: %w", safeInput, err)
 	}
 
+	if safeCert != "" && !forceKubeseal {
+		sealed, err := sealNative(secretYAML, safeCert, scope)
+		if err != nil {
+			return err
+		}
+		return writeOutput(outputPath, sealed)
+	}
+
 	sealed, err := sealSecret(secretYAML, sealOptions{
 		kubesealPath:        kubesealPath,
 		controllerName:      controllerName,
@@ -97,6 +114,48 @@ type sealOptions struct {
 	scope               string
 }
 
+// sealNative encrypts secretYAML in-process with the RSA public key from
+// certPath and returns the SealedSecret YAML. An empty scope falls back to the
+// scope annotations on the secret.
+func sealNative(secretYAML []byte, certPath, scopeName string) ([]byte, error) {
+	s, err := manifest.FromYAML(secretYAML)
+	if err != nil {
+		return nil, fmt.Errorf("load secret: %w", err)
+	}
+	if err := checkLimits(s); err != nil {
+		return nil, err
+	}
+
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("read cert %q: %w", certPath, err)
+	}
+	pub, err := sealedsecret.ParsePublicKey(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("--cert: %w", err)
+	}
+
+	scope := sealedsecret.ScopeFromAnnotations(s.Annotations)
+	if scopeName != "" {
+		scope, err = sealedsecret.ParseScope(scopeName)
+		if err != nil {
+			return nil, fmt.Errorf("--scope: %w", err)
+		}
+	}
+
+	ss, err := sealedsecret.Seal(s, pub, scope)
+	if err != nil {
+		return nil, err
+	}
+	out, err := sealedsecret.ToYAML(ss)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Sealed successfully\n")
+	return out, nil
+}
+
 // sealSecret pipes secretYAML through kubeseal and returns the SealedSecret YAML.
 func sealSecret(secretYAML []byte, opts sealOptions) ([]byte, error) {
 	resolved, err := exec.LookPath(opts.kubesealPath)
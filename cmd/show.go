@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"sort"
 
+	corev1 "k8s.io/api/core/v1"
+
 	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	"github.com/pbsladek/k8s-secret-manifest/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -14,8 +17,15 @@ var listCmd = &cobra.Command{
 	Long: `List the key names present in the data: field of a Secret manifest.
 Values are not decoded or displayed.
 
+--input may be a file (optionally a multi-document stream), a directory
+(searched recursively for *.yaml and *.yml), or a glob. Every Secret found is
+listed; other documents are ignored. Use --name and the global --namespace
+flag to narrow the selection.
+
 Example:
-  k8s-secret-manifest list --input secret.yaml`,
+  k8s-secret-manifest list --input secret.yaml
+  k8s-secret-manifest list --input ./secrets/
+  k8s-secret-manifest list --input 'overlays/*/secrets.yaml' --namespace prod`,
 	RunE: runList,
 }
 
@@ -24,67 +34,100 @@ var showCmd = &cobra.Command{
 	Short: "Show decoded values from a Secret manifest",
 	Long: `Decode and display metadata and data key/value pairs from a Secret manifest.
 
-All data values are base64-decoded and printed as plain text.
+Data values are base64-decoded but masked by default: each is shown as its
+length and a short SHA-256 fingerprint. Pass --reveal to print every value in
+clear text, or --reveal-key KEY to reveal selected keys. --key always prints
+the raw value, for scripting.
+
+--input may be a file, multi-document stream, directory, or glob (see list).
+--key requires the selection to resolve to exactly one Secret.
 
 Example:
   k8s-secret-manifest show --input secret.yaml
-  k8s-secret-manifest show --input secret.yaml --key API_KEY`,
+  k8s-secret-manifest show --input secret.yaml --key API_KEY
+  k8s-secret-manifest show --input secrets.yaml --name db --key PASSWORD`,
 	RunE: runShow,
 }
 
 func init() {
-	listCmd.Flags().StringP("input", "i", "", "Input secret manifest file (required)")
+	listCmd.Flags().StringP("input", "i", "", "Input secret manifest file, directory, or glob (required)")
 	_ = listCmd.MarkFlagRequired("input")
+	listCmd.Flags().StringP("name", "N", "", "Only include Secrets with this name")
 
-	showCmd.Flags().StringP("input", "i", "", "Input secret manifest file (required)")
+	showCmd.Flags().StringP("input", "i", "", "Input secret manifest file, directory, or glob (required)")
 	_ = showCmd.MarkFlagRequired("input")
-	showCmd.Flags().StringP("key", "k", "", "Show only this key (default: show all)")
+	showCmd.Flags().StringP("name", "N", "", "Only include Secrets with this name")
+	showCmd.Flags().StringP("key", "k", "", "Print only this key's raw value (default: show all, masked)")
+	addRevealFlags(showCmd)
+	addAgeIdentityFlag(showCmd)
 }
 
 func runList(cmd *cobra.Command, _ []string) error {
 	inputPath, _ := cmd.Flags().GetString("input")
+	name, _ := cmd.Flags().GetString("name")
 
-	safeInput, err := safePath("--input", inputPath)
+	format, err := outputFormat(cmd)
 	if err != nil {
 		return err
 	}
 
-	s, err := manifest.FromFile(safeInput)
+	secrets, err := loadSecrets(cmd, "--input", inputPath, name)
 	if err != nil {
 		return fmt.Errorf("load secret: %w", err)
 	}
 
-	keys := make([]string, 0, len(s.Data))
-	for k := range s.Data {
-		keys = append(keys, k)
+	if format != formatText {
+		doc := report.List{Secrets: make([]report.SecretKeys, 0, len(secrets))}
+		for _, src := range secrets {
+			entry := report.SecretKeys{
+				Source: sourceOf(src),
+				Type:   string(src.Secret.Type),
+				Keys:   make([]report.KeyInfo, 0, len(src.Secret.Data)),
+			}
+			for _, k := range sortedDataKeys(src.Secret) {
+				entry.Keys = append(entry.Keys, report.KeyInfo{Name: k, Size: len(src.Secret.Data[k])})
+			}
+			doc.Secrets = append(doc.Secrets, entry)
+		}
+		return printStructured(format, doc)
 	}
-	sort.Strings(keys)
 
-	fmt.Printf("Secret: %s/%s  type: %s  (%d key(s))\n",
-		s.Namespace, s.Name, s.Type, len(keys))
-	for _, k := range keys {
-		fmt.Printf("  %s\n", k)
+	for i, src := range secrets {
+		printSourceHeader(i, len(secrets), src.Path)
+		s := src.Secret
+		keys := sortedDataKeys(s)
+
+		fmt.Printf("Secret: %s/%s  type: %s  (%d key(s))\n",
+			s.Namespace, s.Name, s.Type, len(keys))
+		for _, k := range keys {
+			fmt.Printf("  %s\n", k)
+		}
 	}
 	return nil
 }
 
 func runShow(cmd *cobra.Command, _ []string) error {
 	inputPath, _ := cmd.Flags().GetString("input")
+	name, _ := cmd.Flags().GetString("name")
 	onlyKey, _ := cmd.Flags().GetString("key")
 
-	safeInput, err := safePath("--input", inputPath)
+	format, err := outputFormat(cmd)
 	if err != nil {
 		return err
 	}
 
-	s, err := manifest.FromFile(safeInput)
+	secrets, err := loadSecrets(cmd, "--input", inputPath, name)
 	if err != nil {
 		return fmt.Errorf("load secret: %w", err)
 	}
 
 	// Single-key mode: print just the value for scripting convenience.
 	if onlyKey != "" {
-		val, err := manifest.GetPlainValue(s, onlyKey)
+		if len(secrets) > 1 {
+			return fmt.Errorf("--key requires a single Secret, but %d were found; select one with --name or --namespace",
+				len(secrets))
+		}
+		val, err := manifest.GetPlainValue(secrets[0].Secret, onlyKey)
 		if err != nil {
 			return err
 		}
@@ -92,7 +135,24 @@ func runShow(cmd *cobra.Command, _ []string) error {
 		return nil
 	}
 
-	// Full display.
+	r := newRedactor(cmd)
+	if format != formatText {
+		doc := report.Show{Secrets: make([]report.SecretView, 0, len(secrets))}
+		for _, src := range secrets {
+			doc.Secrets = append(doc.Secrets, viewSecret(src, r))
+		}
+		return printStructured(format, doc)
+	}
+
+	for i, src := range secrets {
+		printSourceHeader(i, len(secrets), src.Path)
+		printSecret(src.Secret, r)
+	}
+	return nil
+}
+
+// printSecret prints metadata and decoded data values of s, masked by r.
+func printSecret(s *corev1.Secret, r *redactor) {
 	fmt.Printf("Secret: %s/%s\n", s.Namespace, s.Name)
 	fmt.Printf("  type: %s\n", s.Type)
 
@@ -114,21 +174,59 @@ func runShow(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	fmt.Printf("  data:\n")
+	for _, k := range sortedDataKeys(s) {
+		fmt.Printf("    %s: %s\n", k, r.value(k, s.Data[k]))
+	}
+}
+
+// viewSecret builds the structured show entry for src, masked by r.
+func viewSecret(src sourcedSecret, r *redactor) report.SecretView {
+	s := src.Secret
+	v := report.SecretView{
+		Source:      sourceOf(src),
+		Type:        string(s.Type),
+		Immutable:   s.Immutable != nil && *s.Immutable,
+		Labels:      s.Labels,
+		Annotations: s.Annotations,
+		Data:        make([]report.DataEntry, 0, len(s.Data)),
+	}
+	if v.Labels == nil {
+		v.Labels = map[string]string{}
+	}
+	if v.Annotations == nil {
+		v.Annotations = map[string]string{}
+	}
+	for _, k := range sortedDataKeys(s) {
+		v.Data = append(v.Data, report.DataEntry{Key: k, Value: r.reportValue(k, s.Data[k])})
+	}
+	return v
+}
+
+func sourceOf(src sourcedSecret) report.Source {
+	return report.Source{File: src.Path, Namespace: src.Secret.Namespace, Name: src.Secret.Name}
+}
+
+// printSourceHeader separates per-Secret output when a command reports on
+// more than one Secret. Nothing is printed for a single Secret so the output
+// stays identical to the single-file case.
+func printSourceHeader(i, total int, path string) {
+	if total <= 1 {
+		return
+	}
+	if i > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("# %s\n", path)
+}
+
+func sortedDataKeys(s *corev1.Secret) []string {
 	keys := make([]string, 0, len(s.Data))
 	for k := range s.Data {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
-
-	fmt.Printf("  data:\n")
-	for _, k := range keys {
-		val, err := manifest.GetPlainValue(s, k)
-		if err != nil {
-			return err
-		}
-		fmt.Printf("    %s: %s\n", k, val)
-	}
-	return nil
+	return keys
 }
 
 func sortedStringKeys(m map[string]string) []string {
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	"github.com/pbsladek/k8s-secret-manifest/internal/sops"
+	"github.com/spf13/cobra"
+)
+
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt a Secret manifest with SOPS/age",
+	Long: `Encrypt the data values of a Secret manifest for one or more age recipients.
+
+The output is a SOPS-format file: only data and stringData are encrypted
+(encrypted_regex ^(data|stringData)$), so apiVersion, kind, and metadata stay
+readable in git. It can be decrypted by sops, by Flux's SOPS integration, or
+by the decrypt command.
+
+Recipients default to the comma-separated SOPS_AGE_RECIPIENTS environment
+variable when --age-recipient is not given.
+
+Example:
+  k8s-secret-manifest encrypt \
+    --input secret.yaml \
+    --output secret.enc.yaml \
+    --age-recipient age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p`,
+	RunE: runEncrypt,
+}
+
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt a SOPS/age-encrypted Secret manifest",
+	Long: `Decrypt a SOPS-format Secret manifest with an age identity.
+
+The MAC is verified, so a file edited without sops is rejected.
+The identity file defaults to SOPS_AGE_KEY_FILE, or the inline key in
+SOPS_AGE_KEY, when --age-identity is not given.
+
+The output contains unencrypted values; do not commit it.
+
+Example:
+  k8s-secret-manifest decrypt \
+    --input secret.enc.yaml \
+    --age-identity ~/.config/sops/age/keys.txt`,
+	RunE: runDecrypt,
+}
+
+func init() {
+	encryptCmd.Flags().StringP("input", "i", "", "Input plain secret manifest file (required)")
+	_ = encryptCmd.MarkFlagRequired("input")
+	encryptCmd.Flags().StringP("output", "o", "", "Output encrypted file (default: stdout)")
+	encryptCmd.Flags().StringArrayP("age-recipient", "r", nil,
+		"age public key (age1...) to encrypt for; repeatable (default: $SOPS_AGE_RECIPIENTS)")
+	encryptCmd.Flags().String("encrypted-regex", sops.DefaultEncryptedRegex,
+		"Only keys matching this regex (and everything below them) are encrypted")
+
+	decryptCmd.Flags().StringP("input", "i", "", "Input SOPS-encrypted manifest file (required)")
+	_ = decryptCmd.MarkFlagRequired("input")
+	decryptCmd.Flags().StringP("output", "o", "", "Output plain secret file (default: stdout)")
+	addAgeIdentityFlag(decryptCmd)
+}
+
+// addAgeIdentityFlag registers --age-identity on commands that read
+// SOPS-encrypted manifests.
+func addAgeIdentityFlag(cmd *cobra.Command) {
+	cmd.Flags().String("age-identity", "",
+		"age identity file for SOPS-encrypted input (default: $SOPS_AGE_KEY_FILE or $SOPS_AGE_KEY)")
+}
+
+func runEncrypt(cmd *cobra.Command, _ []string) error {
+	inputPath, _ := cmd.Flags().GetString("input")
+	outputPath, _ := cmd.Flags().GetString("output")
+	recipients, _ := cmd.Flags().GetStringArray("age-recipient")
+	encryptedRegex, _ := cmd.Flags().GetString("encrypted-regex")
+
+	if len(recipients) == 0 {
+		for _, r := range strings.Split(os.Getenv("SOPS_AGE_RECIPIENTS"), ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				recipients = append(recipients, r)
+			}
+		}
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("--age-recipient is required (or set SOPS_AGE_RECIPIENTS)")
+	}
+
+	safeInput, err := safePath("--input", inputPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(safeInput)
+	if err != nil {
+		return fmt.Errorf("read input file %q: %w", safeInput, err)
+	}
+	if sops.IsEncrypted(data) {
+		return fmt.Errorf("%s is already SOPS-encrypted", safeInput)
+	}
+	s, err := manifest.FromYAML(data)
+	if err != nil {
+		return fmt.Errorf("load secret: %w", err)
+	}
+
+	key, err := sops.NewKey(recipients, encryptedRegex)
+	if err != nil {
+		return err
+	}
+	if err := writeSecretFile(outputPath, s, manifest.EmitData, key); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Encrypted %d key(s) for %d recipient(s)\n", len(s.Data), len(recipients))
+	return nil
+}
+
+func runDecrypt(cmd *cobra.Command, _ []string) error {
+	inputPath, _ := cmd.Flags().GetString("input")
+	outputPath, _ := cmd.Flags().GetString("output")
+
+	safeInput, err := safePath("--input", inputPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(safeInput)
+	if err != nil {
+		return fmt.Errorf("read input file %q: %w", safeInput, err)
+	}
+	if !sops.IsEncrypted(data) {
+		return fmt.Errorf("%s is not SOPS-encrypted", safeInput)
+	}
+
+	plain, _, err := readManifest(cmd, safeInput)
+	if err != nil {
+		return err
+	}
+	return writeOutput(outputPath, plain)
+}
+
+// ageIdentities loads the age identities for decrypting SOPS files from
+// --age-identity, SOPS_AGE_KEY_FILE, or SOPS_AGE_KEY, in that order.
+// It returns nil when none is configured.
+func ageIdentities(cmd *cobra.Command) ([]age.Identity, error) {
+	path := ""
+	if f := cmd.Flags().Lookup("age-identity"); f != nil {
+		path = f.Value.String()
+	}
+	if path == "" {
+		path = os.Getenv("SOPS_AGE_KEY_FILE")
+	}
+	if path == "" {
+		if inline := os.Getenv("SOPS_AGE_KEY"); inline != "" {
+			return sops.ParseIdentities([]byte(inline))
+		}
+		return nil, nil
+	}
+
+	safe, err := safePath("--age-identity", path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(safe)
+	if err != nil {
+		return nil, fmt.Errorf("read age identity %q: %w", safe, err)
+	}
+	ids, err := sops.ParseIdentities(data)
+	if err != nil {
+		return nil, fmt.Errorf("--age-identity: %w", err)
+	}
+	return ids, nil
+}
+
+// readManifest reads path, transparently decrypting it if it is a SOPS file.
+// The returned Key is non-nil only for SOPS files and re-encrypts for the
+// same recipients on write.
+func readManifest(cmd *cobra.Command, path string) ([]byte, *sops.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read file %q: %w", path, err)
+	}
+	if !sops.IsEncrypted(data) {
+		return data, nil, nil
+	}
+
+	ids, err := ageIdentities(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil, fmt.Errorf("%s is SOPS-encrypted: pass --age-identity or set SOPS_AGE_KEY_FILE", path)
+	}
+	plain, key, err := sops.Decrypt(data, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypt %s: %w", path, err)
+	}
+	return plain, key, nil
+}
+
+// loadSecretFile reads the Secret to change from a manifest (see
+// selectSecret), decrypting SOPS files. A SOPS file must hold one document.
+func loadSecretFile(cmd *cobra.Command, path string) (*corev1.Secret, *sops.Key, error) {
+	data, key, err := readManifest(cmd, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n := len(manifest.SplitDocuments(data)); key != nil && n > 1 {
+		return nil, nil, fmt.Errorf("%s: changing one Secret of a SOPS-encrypted stream of %d documents is not supported", path, n)
+	}
+	s, err := selectSecret(cmd, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, key, nil
+}
+
+// writeSecretFile is writeSecretAs that SOPS-encrypts the output with key
+// when key is non-nil.
+func writeSecretFile(path string, s *corev1.Secret, emit string, key *sops.Key) error {
+	if key == nil {
+		return writeSecretAs(path, s, emit)
+	}
+	if err := checkLimits(s); err != nil {
+		return err
+	}
+	data, err := manifest.ToYAMLAs(s, emit)
+	if err != nil {
+		return err
+	}
+	enc, err := key.Encrypt(data)
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+	return writeOutput(path, enc)
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/report"
+	"github.com/pbsladek/k8s-secret-manifest/internal/rotation"
+	"github.com/spf13/cobra"
+)
+
+var staleCmd = &cobra.Command{
+	Use:   "stale",
+	Short: "List keys older than their maximum age",
+	Long: `List data keys whose last rotation is older than their maximum age.
+
+rotate, update, and add-entry record rotations with --stamp: each changed key
+gets a last-rotated timestamp and a rotation count in annotations, and
+--max-age sets how long the key may go without rotation. stale reads those
+annotations across every Secret found in --input. A key with a maximum age
+that was never stamped is stale as well.
+
+--default-max-age applies to every data key without its own max-age,
+including keys that were never stamped, so a whole repository can be held to
+a single policy.
+
+The command exits non-zero when any key is stale, so a scheduled CI job can
+open rotation tickets from its output (use the global --output-format json).
+
+Example:
+  k8s-secret-manifest stale --input ./secrets/
+  k8s-secret-manifest stale --input ./secrets/ --default-max-age 90d --all`,
+	RunE: runStale,
+}
+
+func init() {
+	staleCmd.Flags().StringP("input", "i", "", "Input secret manifest file, directory, or glob (required)")
+	_ = staleCmd.MarkFlagRequired("input")
+	staleCmd.Flags().StringP("name", "N", "", "Only check Secrets with this name")
+	staleCmd.Flags().String("default-max-age", "",
+		"Maximum age for keys without their own max-age annotation (e.g. 90d or 36h)")
+	staleCmd.Flags().Bool("all", false, "List every tracked key, not only stale ones")
+	addAgeIdentityFlag(staleCmd)
+}
+
+func runStale(cmd *cobra.Command, _ []string) error {
+	inputPath, _ := cmd.Flags().GetString("input")
+	name, _ := cmd.Flags().GetString("name")
+	defaultFlag, _ := cmd.Flags().GetString("default-max-age")
+	all, _ := cmd.Flags().GetBool("all")
+
+	var defaultMaxAge time.Duration
+	if defaultFlag != "" {
+		var err error
+		if defaultMaxAge, err = rotation.ParseMaxAge(defaultFlag); err != nil {
+			return fmt.Errorf("--default-max-age: %w", err)
+		}
+	}
+
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := loadSecrets(cmd, "--input", inputPath, name)
+	if err != nil {
+		return fmt.Errorf("load secret: %w", err)
+	}
+
+	now := time.Now()
+	doc := report.Staleness{Keys: []report.KeyRotation{}}
+	for _, src := range secrets {
+		statuses, err := rotation.Statuses(src.Secret, defaultMaxAge)
+		if err != nil {
+			return fmt.Errorf("%s: %s/%s: %w", src.Path, src.Secret.Namespace, src.Secret.Name, err)
+		}
+		for _, st := range statuses {
+			stale := st.Stale(now)
+			if stale {
+				doc.Stale++
+			}
+			if !stale && !all {
+				continue
+			}
+			doc.Keys = append(doc.Keys, keyRotation(sourceOf(src), st, stale))
+		}
+	}
+
+	if format != formatText {
+		if err := printStructured(format, doc); err != nil {
+			return err
+		}
+	} else {
+		for _, k := range doc.Keys {
+			fmt.Println(describeRotation(k, now))
+		}
+	}
+
+	if doc.Stale > 0 {
+		return fmt.Errorf("%d stale key(s)", doc.Stale)
+	}
+	if format == formatText {
+		fmt.Fprintf(os.Stderr, "no stale keys\n")
+	}
+	return nil
+}
+
+func keyRotation(src report.Source, st rotation.Status, stale bool) report.KeyRotation {
+	k := report.KeyRotation{Source: src, Key: st.Key, Count: st.Count, Stale: stale}
+	if !st.LastRotated.IsZero() {
+		k.LastRotated = st.LastRotated.UTC().Format(time.RFC3339)
+	}
+	if st.MaxAge > 0 {
+		k.MaxAge = rotation.FormatMaxAge(st.MaxAge)
+	}
+	if due := st.Due(); !due.IsZero() {
+		k.Due = due.UTC().Format(time.RFC3339)
+	}
+	return k
+}
+
+// describeRotation formats one key as a line of text output.
+func describeRotation(k report.KeyRotation, now time.Time) string {
+	state := "ok"
+	if k.Stale {
+		state = "STALE"
+	}
+	line := fmt.Sprintf("%-5s  %s: %s/%s %s", state, k.File, k.Namespace, k.Name, k.Key)
+	if k.LastRotated == "" {
+		line += "  never rotated"
+	} else {
+		line += "  last rotated " + k.LastRotated
+		if t, err := time.Parse(time.RFC3339, k.LastRotated); err == nil {
+			line += fmt.Sprintf(" (%d day(s) ago)", int(now.Sub(t).Hours()/24))
+		}
+	}
+	if k.MaxAge != "" {
+		line += ", max age " + k.MaxAge
+	}
+	return line
+}
+
+// addStampFlags registers --stamp and --max-age.
+func addStampFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("stamp", false,
+		"Record the rotation of every changed key in last-rotated and rotation-count annotations")
+	cmd.Flags().String("max-age", "",
+		"Maximum age of the changed keys before stale reports them (e.g. 90d or 36h); implies --stamp")
+}
+
+// stampFromFlags reads --stamp and --max-age. It returns a nil stamper when
+// stamping was not requested.
+func stampFromFlags(cmd *cobra.Command) (*stamper, error) {
+	stamp, _ := cmd.Flags().GetBool("stamp")
+	maxAgeFlag, _ := cmd.Flags().GetString("max-age")
+	if !stamp && maxAgeFlag == "" {
+		return nil, nil
+	}
+	st := &stamper{now: time.Now()}
+	if maxAgeFlag != "" {
+		var err error
+		if st.maxAge, err = rotation.ParseMaxAge(maxAgeFlag); err != nil {
+			return nil, fmt.Errorf("--max-age: %w", err)
+		}
+	}
+	return st, nil
+}
+
+// stamper records key rotations in a Secret's annotations. A nil stamper
+// does nothing, so callers need not check whether --stamp was given.
+type stamper struct {
+	now    time.Time
+	maxAge time.Duration
+}
+
+func (st *stamper) stamp(s *corev1.Secret, keys ...string) error {
+	if st == nil {
+		return nil
+	}
+	for _, key := range keys {
+		if err := rotation.Stamp(s, key, st.now, st.maxAge); err != nil {
+			return fmt.Errorf("--stamp: %w", err)
+		}
+	}
+	return nil
+}
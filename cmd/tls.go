@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/certgen"
+	"github.com/pbsladek/k8s-secret-manifest/internal/keygen"
+	"github.com/spf13/cobra"
+)
+
+// addTLSIssueFlags registers the flags describing a certificate to issue
+// from scratch.
+func addTLSIssueFlags(cmd *cobra.Command) {
+	cmd.Flags().String("tls-cn", "", "Issue a certificate with this subject common name; sets type=kubernetes.io/tls")
+	cmd.Flags().StringArray("tls-san", nil,
+		"DNS name or IP address to add as a subject alternative name; repeatable; implies issuing")
+	cmd.Flags().StringArray("tls-org", nil, "Subject organization of the issued certificate; repeatable")
+	cmd.Flags().String("tls-key-type", "ecdsa:p256",
+		"Key for the issued certificate: rsa[:BITS], ecdsa[:p256|p384|p521], or ed25519")
+	cmd.Flags().Bool("tls-is-ca", false, "Issue a CA certificate that can sign other certificates")
+	cmd.Flags().Int("tls-days", int(certgen.DefaultValidity.Hours()/24), "Validity of the issued certificate in days")
+}
+
+// addTLSCAFlags registers the flags selecting the CA that signs an issued
+// certificate.
+func addTLSCAFlags(cmd *cobra.Command) {
+	cmd.Flags().String("tls-ca-secret", "",
+		"Secret manifest whose tls.crt/tls.key is the CA that signs the certificate (default: self-signed)")
+	cmd.Flags().String("tls-ca-cert", "", "CA certificate PEM file that signs the certificate (with --tls-ca-key)")
+	cmd.Flags().String("tls-ca-key", "", "CA private key PEM file (with --tls-ca-cert)")
+	cmd.Flags().Bool("tls-include-ca", false, "Also write the CA certificate to ca.crt")
+}
+
+// tlsIssueRequested reports whether any certificate-issuing flag was given.
+func tlsIssueRequested(cmd *cobra.Command) bool {
+	for _, name := range []string{"tls-cn", "tls-san", "tls-org", "tls-key-type", "tls-is-ca", "tls-days",
+		"tls-ca-secret", "tls-ca-cert", "tls-ca-key", "tls-include-ca"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsIssueRequest builds a certificate request from the flags registered
+// with addTLSIssueFlags.
+func tlsIssueRequest(cmd *cobra.Command) (certgen.Request, error) {
+	cn, _ := cmd.Flags().GetString("tls-cn")
+	sans, _ := cmd.Flags().GetStringArray("tls-san")
+	orgs, _ := cmd.Flags().GetStringArray("tls-org")
+	keyType, _ := cmd.Flags().GetString("tls-key-type")
+	isCA, _ := cmd.Flags().GetBool("tls-is-ca")
+
+	validity, err := tlsValidity(cmd)
+	if err != nil {
+		return certgen.Request{}, err
+	}
+	spec, err := keygen.ParseSpec(keyType)
+	if err != nil {
+		return certgen.Request{}, fmt.Errorf("--tls-key-type: %w", err)
+	}
+	req := certgen.Request{
+		CommonName:   cn,
+		Organization: orgs,
+		Validity:     validity,
+		Key:          spec,
+		IsCA:         isCA,
+	}
+	for _, san := range sans {
+		req.AddSAN(strings.TrimSpace(san))
+	}
+	return req, nil
+}
+
+// tlsValidity returns --tls-days as a duration; 0 leaves the choice to the
+// caller.
+func tlsValidity(cmd *cobra.Command) (time.Duration, error) {
+	days, _ := cmd.Flags().GetInt("tls-days")
+	if days < 0 {
+		return 0, fmt.Errorf("--tls-days must not be negative")
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// loadTLSCA loads the CA selected by addTLSCAFlags, returning nil for a
+// self-signed certificate. --tls-ca-secret accepts SOPS-encrypted manifests.
+func loadTLSCA(cmd *cobra.Command) (*certgen.CA, error) {
+	secretPath, _ := cmd.Flags().GetString("tls-ca-secret")
+	certPath, _ := cmd.Flags().GetString("tls-ca-cert")
+	keyPath, _ := cmd.Flags().GetString("tls-ca-key")
+
+	switch {
+	case secretPath != "" && (certPath != "" || keyPath != ""):
+		return nil, fmt.Errorf("--tls-ca-secret cannot be combined with --tls-ca-cert/--tls-ca-key")
+	case secretPath != "":
+		safe, err := safePath("--tls-ca-secret", secretPath)
+		if err != nil {
+			return nil, err
+		}
+		s, _, err := loadSecretFile(cmd, safe)
+		if err != nil {
+			return nil, fmt.Errorf("--tls-ca-secret: %w", err)
+		}
+		crt, key := s.Data[corev1.TLSCertKey], s.Data[corev1.TLSPrivateKeyKey]
+		if crt == nil || key == nil {
+			return nil, fmt.Errorf("--tls-ca-secret: %s/%s has no %s and %s",
+				s.Namespace, s.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+		}
+		ca, err := certgen.LoadCA(crt, key)
+		if err != nil {
+			return nil, fmt.Errorf("--tls-ca-secret: %w", err)
+		}
+		return ca, nil
+	case certPath != "" || keyPath != "":
+		if certPath == "" || keyPath == "" {
+			return nil, fmt.Errorf("--tls-ca-cert and --tls-ca-key must both be provided")
+		}
+		crt, err := readFlagFile("--tls-ca-cert", certPath)
+		if err != nil {
+			return nil, err
+		}
+		key, err := readFlagFile("--tls-ca-key", keyPath)
+		if err != nil {
+			return nil, err
+		}
+		ca, err := certgen.LoadCA(crt, key)
+		if err != nil {
+			return nil, fmt.Errorf("--tls-ca-cert: %w", err)
+		}
+		return ca, nil
+	}
+	return nil, nil
+}
+
+func readFlagFile(flag, path string) ([]byte, error) {
+	safe, err := safePath(flag, path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(safe)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", flag, err)
+	}
+	return data, nil
+}
+
+// storeIssued writes an issued certificate and key to tls.crt and tls.key.
+// With includeCA, ca.crt receives the CA certificate, or the certificate
+// itself when it is self-signed.
+func storeIssued(s *corev1.Secret, issued *certgen.Issued, ca *certgen.CA, includeCA bool) {
+	s.Data[corev1.TLSCertKey] = issued.CertPEM
+	s.Data[corev1.TLSPrivateKeyKey] = issued.KeyPEM
+	if includeCA {
+		if ca != nil {
+			s.Data[corev1.ServiceAccountRootCAKey] = ca.CertPEM
+		} else {
+			s.Data[corev1.ServiceAccountRootCAKey] = issued.CertPEM
+		}
+	}
+}
+
+// applyTLSIssue issues a new certificate from the generate flags.
+func applyTLSIssue(cmd *cobra.Command, s *corev1.Secret, explicitType string) error {
+	req, err := tlsIssueRequest(cmd)
+	if err != nil {
+		return err
+	}
+	ca, err := loadTLSCA(cmd)
+	if err != nil {
+		return err
+	}
+	includeCA, _ := cmd.Flags().GetBool("tls-include-ca")
+
+	issued, err := certgen.Issue(req, ca)
+	if err != nil {
+		return fmt.Errorf("issue certificate: %w", err)
+	}
+	if explicitType == "" {
+		s.Type = corev1.SecretTypeTLS
+	}
+	storeIssued(s, issued, ca, includeCA)
+	return nil
+}
+
+// reissueTLS replaces tls.crt and tls.key with a new key and certificate
+// that keeps the subject, SANs, validity period, and key type of the current
+// certificate. A certificate that is not self-signed needs its CA. ca.crt is
+// refreshed when it exists or --tls-include-ca is set.
+func reissueTLS(cmd *cobra.Command, s *corev1.Secret) error {
+	crt, ok := s.Data[corev1.TLSCertKey]
+	if !ok {
+		return fmt.Errorf("--reissue-tls: key %q not found in secret data", corev1.TLSCertKey)
+	}
+	cert, err := certgen.ParseCertificate(crt)
+	if err != nil {
+		return fmt.Errorf("--reissue-tls: %s: %w", corev1.TLSCertKey, err)
+	}
+	req, err := certgen.ReissueRequest(cert)
+	if err != nil {
+		return fmt.Errorf("--reissue-tls: %w", err)
+	}
+	validity, err := tlsValidity(cmd)
+	if err != nil {
+		return err
+	}
+	if validity > 0 {
+		req.Validity = validity
+	}
+
+	ca, err := loadTLSCA(cmd)
+	if err != nil {
+		return err
+	}
+	if ca == nil && !certgen.SelfSigned(cert) {
+		return fmt.Errorf("--reissue-tls: certificate %q was issued by %q: pass --tls-ca-secret or --tls-ca-cert/--tls-ca-key",
+			cert.Subject.CommonName, cert.Issuer.CommonName)
+	}
+
+	issued, err := certgen.Issue(req, ca)
+	if err != nil {
+		return fmt.Errorf("--reissue-tls: %w", err)
+	}
+	includeCA, _ := cmd.Flags().GetBool("tls-include-ca")
+	_, hasCA := s.Data[corev1.ServiceAccountRootCAKey]
+	storeIssued(s, issued, ca, includeCA || hasCA)
+	fmt.Fprintf(os.Stderr, "Reissued certificate %q (%d SAN(s), expires %s)\n", cert.Subject.CommonName,
+		len(req.DNSNames)+len(req.IPAddresses), time.Now().Add(req.Validity).UTC().Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/sealedsecret"
+	"github.com/spf13/cobra"
+)
+
+var unsealCmd = &cobra.Command{
+	Use:   "unseal",
+	Short: "Decrypt a SealedSecret manifest with the controller's private key",
+	Long: `Decrypt a SealedSecret manifest back into a plain Kubernetes Secret.
+
+The private key may be a PEM file (PKCS#1 or PKCS#8) or the key backup the
+controller exports, i.e. the output of:
+
+  kubectl get secret -n kube-system \
+    -l sealedsecrets.bitnami.com/sealed-secrets-key -o yaml
+
+When the backup holds several keys (after key renewal), each is tried in turn.
+The scope (strict, namespace-wide, cluster-wide) is taken from the
+SealedSecret's annotations, matching the controller's behaviour.
+
+The output is a plain Secret that show, diff, and the other commands accept.
+It contains unencrypted values; do not commit it.
+
+Example:
+  k8s-secret-manifest unseal \
+    --input sealed-secret.yaml \
+    --private-key sealed-secrets-key.yaml \
+    --output secret.yaml`,
+	RunE: runUnseal,
+}
+
+func init() {
+	unsealCmd.Flags().StringP("input", "i", "", "Input SealedSecret manifest file (required)")
+	_ = unsealCmd.MarkFlagRequired("input")
+
+	unsealCmd.Flags().StringP("private-key", "k", "",
+		"Path to the controller's private key: PEM, or key backup Secret/List YAML (required)")
+	_ = unsealCmd.MarkFlagRequired("private-key")
+
+	unsealCmd.Flags().StringP("output", "o", "", "Output plain secret file (default: stdout)")
+}
+
+func runUnseal(cmd *cobra.Command, _ []string) error {
+	inputPath, _ := cmd.Flags().GetString("input")
+	keyPath, _ := cmd.Flags().GetString("private-key")
+	outputPath, _ := cmd.Flags().GetString("output")
+
+	safeInput, err := safePath("--input", inputPath)
+	if err != nil {
+		return err
+	}
+	safeKey, err := safePath("--private-key", keyPath)
+	if err != nil {
+		return err
+	}
+
+	sealedYAML, err := os.ReadFile(safeInput)
+	if err != nil {
+		return fmt.Errorf("read input file %q: %w", safeInput, err)
+	}
+	ss, err := sealedsecret.FromYAML(sealedYAML)
+	if err != nil {
+		return fmt.Errorf("load sealed secret: %w", err)
+	}
+
+	keyData, err := os.ReadFile(safeKey)
+	if err != nil {
+		return fmt.Errorf("read private key %q: %w", safeKey, err)
+	}
+	keys, err := sealedsecret.ParsePrivateKeys(keyData)
+	if err != nil {
+		return fmt.Errorf("--private-key: %w", err)
+	}
+
+	s, err := sealedsecret.Unseal(ss, keys)
+	if err != nil {
+		return fmt.Errorf("unseal: %w", err)
+	}
+
+	if err := writeSecretTo(outputPath, s); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Unsealed %d key(s) from %s/%s\n", len(s.Data), s.Namespace, s.Name)
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	"github.com/pbsladek/k8s-secret-manifest/internal/rotation"
 	"github.com/pbsladek/k8s-secret-manifest/internal/validate"
 	"github.com/spf13/cobra"
 )
@@ -24,7 +25,14 @@ Examples:
     --set-file CA_CERT=./ca.crt \
     --delete-key OLD_KEY \
     --label env=prod \
-    --annotation last-rotated=2026-02-27`,
+    --annotation last-rotated=2026-02-27
+
+--stamp records the change of every --set and --set-file key in rotation
+annotations (see stale), and --max-age also sets their maximum age. Rotation
+annotations of deleted keys are removed.
+
+With --policy, the updated Secret is checked against the policy file's rules
+and the file is left untouched if an error-severity rule fails.`,
 	RunE: runUpdate,
 }
 
@@ -34,6 +42,9 @@ func init() {
 
 	updateCmd.Flags().StringP("output", "o", "",
 		"Output file path (default: same as --input)")
+	updateCmd.Flags().Bool("backup", false,
+		"Keep the previous version of the output file as <output>.bak")
+	addSelectFlag(updateCmd)
 
 	updateCmd.Flags().StringArrayP("set", "s", nil,
 		"key=value to set or overwrite; repeatable (e.g. --set API_KEY=newval)")
@@ -46,16 +57,37 @@ func init() {
 		"Label to set or overwrite; repeatable (e.g. --label env=prod)")
 	updateCmd.Flags().StringArrayP("annotation", "a", nil,
 		"Annotation to set or overwrite; repeatable (e.g. --annotation managed-by=me)")
+
+	updateCmd.Flags().String("emit", manifest.EmitData,
+		"Output encoding: data (base64) or string-data (readable stringData:, binary values stay in data:)")
+	addStampFlags(updateCmd)
+	addAgeIdentityFlag(updateCmd)
+	addPolicyFlag(updateCmd)
 }
 
 func runUpdate(cmd *cobra.Command, _ []string) error {
 	inputPath, _ := cmd.Flags().GetString("input")
 	outputPath, _ := cmd.Flags().GetString("output")
+	backup, _ := cmd.Flags().GetBool("backup")
 	sets, _ := cmd.Flags().GetStringArray("set")
 	setFiles, _ := cmd.Flags().GetStringArray("set-file")
 	deleteKeys, _ := cmd.Flags().GetStringArray("delete-key")
 	labels, _ := cmd.Flags().GetStringArray("label")
 	annotations, _ := cmd.Flags().GetStringArray("annotation")
+	emitFlag, _ := cmd.Flags().GetString("emit")
+
+	emit, err := manifest.ParseEmitMode(emitFlag)
+	if err != nil {
+		return fmt.Errorf("--emit: %w", err)
+	}
+	pol, err := loadPolicy(cmd)
+	if err != nil {
+		return err
+	}
+	st, err := stampFromFlags(cmd)
+	if err != nil {
+		return err
+	}
 
 	if outputPath == "" {
 		outputPath = inputPath
@@ -67,7 +99,7 @@ func runUpdate(cmd *cobra.Command, _ []string) error {
 	}
 
 	return withExclusiveLock(outputPath, func() error {
-		s, err := manifest.FromFile(safeInput)
+		s, key, err := loadSecretFile(cmd, safeInput)
 		if err != nil {
 			return fmt.Errorf("load secret: %w", err)
 		}
@@ -81,17 +113,29 @@ func runUpdate(cmd *cobra.Command, _ []string) error {
 				return fmt.Errorf("--set: %w", err)
 			}
 			manifest.SetPlainValue(s, k, v)
+			if err := st.stamp(s, k); err != nil {
+				return err
+			}
 		}
 
 		if err := applySetFiles(s, setFiles); err != nil {
 			return err
 		}
+		if st != nil {
+			for _, kf := range setFiles {
+				k, _, _ := splitKeyValue(kf)
+				if err := st.stamp(s, k); err != nil {
+					return err
+				}
+			}
+		}
 
 		for _, key := range deleteKeys {
 			if _, ok := s.Data[key]; !ok {
 				return fmt.Errorf("--delete-key %q: key not found in secret data", key)
 			}
 			delete(s.Data, key)
+			rotation.Forget(s, key)
 		}
 
 		if len(labels) > 0 {
@@ -120,7 +164,20 @@ func runUpdate(cmd *cobra.Command, _ []string) error {
 			}
 		}
 
-		if err := writeSecretTo(outputPath, s); err != nil {
+		if err := enforcePolicy(pol, s); err != nil {
+			return err
+		}
+
+		if err := checkLimits(s); err != nil {
+			return err
+		}
+		if backup {
+			if err := backupExisting(outputPath); err != nil {
+				return err
+			}
+		}
+
+		if err := writeSecretFile(outputPath, s, emit, key); err != nil {
 			return err
 		}
 
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var updateEntryCmd = &cobra.Command{
+	Use:   "update-entry",
+	Short: "Change the value of an entry in a paired index-list secret",
+	Long: `Change the value paired with an identifier in a secret that stores two
+parallel semicolon-separated lists in two data keys, matched by index position.
+
+The entry keeps its position in both lists. For tables of three or more
+parallel lists, repeat --entries-val for every value column and give one
+--value per column, in the same order.
+
+  k8s-secret-manifest update-entry \
+    --input secret.yaml \
+    --entries-key  BACKEND_USERS \
+    --entries-val  BACKEND_PASSWORDS \
+    --key bob \
+    --value newpass
+
+Change a row of a users/passwords/databases table:
+  k8s-secret-manifest update-entry \
+    --input secret.yaml \
+    --entries-key  DB_USERS \
+    --entries-val  DB_PASSWORDS --entries-val DB_NAMES \
+    --key bob \
+    --value newpass --value reporting
+
+--stamp records the change of the value lists in rotation annotations (see
+stale), and --max-age also sets its maximum age.`,
+	RunE: runUpdateEntry,
+}
+
+func init() {
+	updateEntryCmd.Flags().StringP("input", "i", "", "Input secret manifest file (required)")
+	_ = updateEntryCmd.MarkFlagRequired("input")
+
+	updateEntryCmd.Flags().StringP("output", "o", "",
+		"Output file path (default: same as --input)")
+	updateEntryCmd.Flags().Bool("backup", false,
+		"Keep the previous version of the output file as <output>.bak")
+	addSelectFlag(updateEntryCmd)
+
+	updateEntryCmd.Flags().StringP("entries-key", "K", "",
+		"Data key name holding the semicolon-separated identifier list (required)")
+	_ = updateEntryCmd.MarkFlagRequired("entries-key")
+
+	updateEntryCmd.Flags().StringArrayP("entries-val", "V", nil,
+		"Data key name holding a semicolon-separated value list; repeat for each value column (required)")
+	_ = updateEntryCmd.MarkFlagRequired("entries-val")
+
+	updateEntryCmd.Flags().StringP("key", "k", "", "Identifier of the entry to change (required)")
+	_ = updateEntryCmd.MarkFlagRequired("key")
+
+	updateEntryCmd.Flags().StringArrayP("value", "v", nil,
+		"New value for the entry; repeat once per --entries-val, in the same order (required)")
+	_ = updateEntryCmd.MarkFlagRequired("value")
+
+	updateEntryCmd.Flags().StringP("separator", "S", ";", "Separator used in the list values")
+	addQuoteFlag(updateEntryCmd)
+	addStampFlags(updateEntryCmd)
+}
+
+func runUpdateEntry(cmd *cobra.Command, _ []string) error {
+	inputPath, _ := cmd.Flags().GetString("input")
+	outputPath, _ := cmd.Flags().GetString("output")
+	backup, _ := cmd.Flags().GetBool("backup")
+	entriesKey, _ := cmd.Flags().GetString("entries-key")
+	entriesVals, _ := cmd.Flags().GetStringArray("entries-val")
+	key, _ := cmd.Flags().GetString("key")
+	values := arrayFlag(cmd, "value")
+
+	st, err := stampFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	codec, err := entryCodec(cmd)
+	if err != nil {
+		return err
+	}
+	if len(values) != len(entriesVals) {
+		return fmt.Errorf("--value given %d time(s) for %d --entries-val column(s)", len(values), len(entriesVals))
+	}
+	row := append([]string{key}, values...)
+
+	if outputPath == "" {
+		outputPath = inputPath
+	}
+
+	safeInput, err := safePath("--input", inputPath)
+	if err != nil {
+		return err
+	}
+
+	return withExclusiveLock(outputPath, func() error {
+		s, err := loadSecretToChange(cmd, safeInput)
+		if err != nil {
+			return fmt.Errorf("load secret: %w", err)
+		}
+
+		table, err := loadTable(s, entriesKey, entriesVals, codec)
+		if err != nil {
+			return err
+		}
+		if err := codec.CheckRow(table, row); err != nil {
+			return quoteHint(err)
+		}
+
+		table, err = table.Update(key, values)
+		if err != nil {
+			return err
+		}
+
+		if err := storeTable(s, codec, table); err != nil {
+			return err
+		}
+		if err := st.stamp(s, entriesVals...); err != nil {
+			return err
+		}
+
+		if err := checkLimits(s); err != nil {
+			return err
+		}
+		if backup {
+			if err := backupExisting(outputPath); err != nil {
+				return err
+			}
+		}
+
+		if err := writeSecretTo(outputPath, s); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Updated entry %q in %s\n", key, outputPath)
+		return nil
+	})
+}
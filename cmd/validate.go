@@ -3,8 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
-	"github.com/pbsladek/k8s-secret-manifest/internal/manifest"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/report"
 	"github.com/pbsladek/k8s-secret-manifest/internal/validate"
 	"github.com/spf13/cobra"
 )
@@ -20,60 +24,132 @@ missing required data keys for the secret type, etc.).
 Warnings indicate likely mistakes (empty data section, missing recommended
 keys for the secret type, etc.).
 
+Typed Secrets are also checked for content: kubernetes.io/tls certificates
+and keys must parse as PEM and match each other, and certificates that have
+expired or expire within --cert-expiry-days are reported as warnings;
+.dockerconfigjson must be JSON with an "auths" map whose "auth" values decode
+to username:password; ssh-privatekey must parse as an SSH private key.
+
+--policy loads a YAML policy file of team rules (required labels and
+annotations, forbidden keys, immutability, minimum value entropy) scoped by
+namespace, name, and type patterns. Each violation is reported with the
+rule's severity and its ID as the issue code, after the built-in checks.
+
+--input may be a file, multi-document stream, directory, or glob; every
+Secret found is validated and issues are prefixed with namespace/name when
+more than one Secret is checked.
+
+With the global --output-format json or yaml, a document listing every
+issue with its severity, code, key, file, namespace, and name is printed to
+stdout instead.
+
 Exit codes:
   0  no issues found
   1  one or more errors found (or warnings with no errors)
 
 Example:
-  k8s-secret-manifest validate --input secret.yaml`,
+  k8s-secret-manifest validate --input secret.yaml
+  k8s-secret-manifest validate --input ./secrets/
+  k8s-secret-manifest validate --input ./secrets/ --policy policy.yaml`,
 	RunE: runValidate,
 }
 
 func init() {
-	validateCmd.Flags().StringP("input", "i", "", "Input secret manifest file (required)")
+	validateCmd.Flags().StringP("input", "i", "", "Input secret manifest file, directory, or glob (required)")
 	_ = validateCmd.MarkFlagRequired("input")
+	validateCmd.Flags().StringP("name", "N", "", "Only validate Secrets with this name")
+	validateCmd.Flags().Int("cert-expiry-days", int(validate.DefaultCertExpiryWindow.Hours()/24),
+		"Warn about TLS certificates expiring within this many days (0: only expired)")
+	addPolicyFlag(validateCmd)
 }
 
 func runValidate(cmd *cobra.Command, _ []string) error {
 	inputPath, _ := cmd.Flags().GetString("input")
+	name, _ := cmd.Flags().GetString("name")
+	expiryDays, _ := cmd.Flags().GetInt("cert-expiry-days")
+
+	if expiryDays < 0 {
+		return fmt.Errorf("--cert-expiry-days must not be negative")
+	}
+	opts := validate.Options{CertExpiryWindow: time.Duration(expiryDays) * 24 * time.Hour}
 
-	safeInput, err := safePath("--input", inputPath)
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	pol, err := loadPolicy(cmd)
 	if err != nil {
 		return err
 	}
 
-	s, err := manifest.FromFile(safeInput)
+	secrets, err := loadSecrets(cmd, "--input", inputPath, name)
 	if err != nil {
 		return fmt.Errorf("load secret: %w", err)
 	}
 
-	issues := validate.Secret(s)
+	var findings []report.Finding
+	for _, src := range secrets {
+		issues := validate.SecretWithOptions(src.Secret, opts)
+		if pol != nil {
+			issues = append(issues, pol.Check(src.Secret)...)
+		}
+		for _, issue := range issues {
+			findings = append(findings, report.Finding{Source: sourceOf(src), Issue: issue})
+		}
+	}
+
+	issues := make([]validate.Issue, len(findings))
+	for i, f := range findings {
+		issues[i] = f.Issue
+	}
+	errCount := countErrors(issues)
+
+	if format != formatText {
+		doc := report.Validation{
+			Valid:    errCount == 0,
+			Errors:   errCount,
+			Warnings: len(issues) - errCount,
+			Issues:   findings,
+		}
+		if doc.Issues == nil {
+			doc.Issues = []report.Finding{}
+		}
+		if err := printStructured(format, doc); err != nil {
+			return err
+		}
+		if errCount > 0 {
+			return fmt.Errorf("validation failed with %d error(s)", errCount)
+		}
+		return nil
+	}
 
 	useColor := os.Getenv("NO_COLOR") == ""
 	colorRed := "\033[31m"
 	colorYellow := "\033[33m"
 	colorReset := "\033[0m"
 
-	hasErrors := false
-	for _, issue := range issues {
-		if issue.IsError() {
-			hasErrors = true
+	for _, f := range findings {
+		msg := f.Message
+		if len(secrets) > 1 {
+			msg = fmt.Sprintf("%s: %s/%s: %s", f.File, f.Namespace, f.Name, msg)
+		}
+		if f.IsError() {
 			if useColor {
-				fmt.Fprintf(os.Stderr, "%serror:%s %s\n", colorRed, colorReset, issue.Message)
+				fmt.Fprintf(os.Stderr, "%serror:%s %s\n", colorRed, colorReset, msg)
 			} else {
-				fmt.Fprintf(os.Stderr, "error: %s\n", issue.Message)
+				fmt.Fprintf(os.Stderr, "error: %s\n", msg)
 			}
 		} else {
 			if useColor {
-				fmt.Fprintf(os.Stderr, "%swarning:%s %s\n", colorYellow, colorReset, issue.Message)
+				fmt.Fprintf(os.Stderr, "%swarning:%s %s\n", colorYellow, colorReset, msg)
 			} else {
-				fmt.Fprintf(os.Stderr, "warning: %s\n", issue.Message)
+				fmt.Fprintf(os.Stderr, "warning: %s\n", msg)
 			}
 		}
 	}
 
-	if hasErrors {
-		return fmt.Errorf("validation failed with %d error(s)", countErrors(issues))
+	if errCount > 0 {
+		return fmt.Errorf("validation failed with %d error(s)", errCount)
 	}
 	if len(issues) > 0 {
 		fmt.Fprintf(os.Stderr, "validation passed with %d warning(s)\n", len(issues))
@@ -92,3 +168,19 @@ func countErrors(issues []validate.Issue) int {
 	}
 	return n
 }
+
+// checkLimits returns an error listing every API server limit s violates, so
+// that mutating commands fail before writing a manifest kubectl apply would
+// reject.
+func checkLimits(s *corev1.Secret) error {
+	issues := validate.Limits(s)
+	if len(issues) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(issues))
+	for i, issue := range issues {
+		msgs[i] = issue.Message
+	}
+	return fmt.Errorf("secret %s/%s violates Kubernetes limits: %s",
+		s.Namespace, s.Name, strings.Join(msgs, "; "))
+}
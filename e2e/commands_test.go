@@ -3,6 +3,10 @@
 package e2e_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -10,6 +14,75 @@ import (
 // ── generate ─────────────────────────────────────────────────────────────────
 
 func TestGenerate(t *testing.T) {
+	t.Run("Keygen", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "deploy",
+			"--keygen", "ssh-privatekey=ssh-ed25519", "--public-key", "ssh-privatekey=ssh-publickey",
+			"--keygen", "jwt.key=ecdsa", "--public-key", "jwt.key=jwt.pub",
+			"--keygen", "AES_KEY=bytes:32:hex", "--output", "secret.yaml")
+
+		out, _ := mustRunDir(t, dir, "show", "--input", "secret.yaml")
+		assertContains(t, out, "kubernetes.io/ssh-auth")
+		mustRunDir(t, dir, "validate", "--input", "secret.yaml")
+		assertContains(t, showKey(t, dir, "secret.yaml", "ssh-privatekey"), "BEGIN OPENSSH PRIVATE KEY")
+		assertContains(t, showKey(t, dir, "secret.yaml", "ssh-publickey"), "ssh-ed25519 ")
+		assertContains(t, showKey(t, dir, "secret.yaml", "jwt.key"), "BEGIN PRIVATE KEY")
+		assertContains(t, showKey(t, dir, "secret.yaml", "jwt.pub"), "BEGIN PUBLIC KEY")
+		if key := showKey(t, dir, "secret.yaml", "AES_KEY"); len(key) != 64 {
+			t.Errorf("expected 64 hex characters, got %q", key)
+		}
+	})
+
+	t.Run("KeygenPublicKeyWithoutPublicHalf", func(t *testing.T) {
+		dir := t.TempDir()
+		_, stderr := mustFailDir(t, dir, "generate", "--name", "s",
+			"--keygen", "AES_KEY=bytes:32", "--public-key", "AES_KEY=AES_PUB")
+		assertContains(t, stderr, "has no public key")
+	})
+
+	t.Run("IssueSelfSignedTLS", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "web",
+			"--tls-cn", "web", "--tls-san", "web.internal", "--tls-san", "10.0.0.5",
+			"--tls-include-ca", "--output", "secret.yaml")
+
+		out, _ := mustRunDir(t, dir, "show", "--input", "secret.yaml")
+		assertContains(t, out, "kubernetes.io/tls")
+		mustRunDir(t, dir, "validate", "--input", "secret.yaml")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "ca.crt"), showKey(t, dir, "secret.yaml", "tls.crt"))
+	})
+
+	t.Run("IssueCASignedTLS", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "ca",
+			"--tls-cn", "Dev CA", "--tls-is-ca", "--tls-days", "3650", "--output", "ca.yaml")
+		mustRunDir(t, dir, "generate", "--name", "web",
+			"--tls-san", "web.internal", "--tls-ca-secret", "ca.yaml", "--tls-include-ca", "--output", "secret.yaml")
+
+		mustRunDir(t, dir, "validate", "--input", "secret.yaml")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "ca.crt"), showKey(t, dir, "ca.yaml", "tls.crt"))
+	})
+
+	t.Run("IssueTLSNotCA", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "leaf", "--tls-cn", "leaf", "--output", "leaf.yaml")
+		_, stderr := mustFailDir(t, dir, "generate", "--name", "web",
+			"--tls-cn", "web", "--tls-ca-secret", "leaf.yaml")
+		assertContains(t, stderr, "not a CA")
+	})
+
+	t.Run("RandomValues", func(t *testing.T) {
+		dir := t.TempDir()
+		_, stderr := mustRunDir(t, dir, "generate", "--name", "s",
+			"--random", "A", "--random", "B", "--length", "20", "--charset", "hex", "--output", "secret.yaml")
+		assertContains(t, stderr, "80 bits of entropy")
+
+		a, b := showKey(t, dir, "secret.yaml", "A"), showKey(t, dir, "secret.yaml", "B")
+		if len(a) != 20 || len(b) != 20 || a == b {
+			t.Errorf("unexpected random values %q, %q", a, b)
+		}
+	})
+
 	t.Run("BasicSet", func(t *testing.T) {
 		dir := t.TempDir()
 		mustRunDir(t, dir, "generate", "--name", "my-secret",
@@ -88,6 +161,17 @@ func TestGenerate(t *testing.T) {
 		assertContains(t, out, "kind: Secret")
 	})
 
+	t.Run("EmitStringData", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s", "--set", "API_KEY=abc",
+			"--emit", "string-data", "--output", "secret.yaml")
+
+		yaml := readFile(t, dir, "secret.yaml")
+		assertContains(t, yaml, "stringData:")
+		assertContains(t, yaml, "API_KEY: abc")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "API_KEY"), "abc")
+	})
+
 	t.Run("InvalidKeyName", func(t *testing.T) {
 		dir := t.TempDir()
 		_, stderr := mustFailDir(t, dir, "generate", "--name", "s",
@@ -142,6 +226,38 @@ func TestFromEnv(t *testing.T) {
 
 		assertEqual(t, showKey(t, dir, "secret.yaml", "KEY"), "quoted value")
 	})
+
+	t.Run("MultiLineAndInterpolation", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, ".env", "HOST=db # primary\n"+
+			"URL=\"postgres://${HOST}/app\"\n"+
+			"PEM=\"-----BEGIN KEY-----\nAAAA\n-----END KEY-----\"\n")
+		mustRunDir(t, dir, "from-env", "--name", "s",
+			"--env-file", ".env", "--output", "secret.yaml")
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "URL"), "postgres://db/app")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PEM"), "-----BEGIN KEY-----\nAAAA\n-----END KEY-----")
+	})
+
+	t.Run("DuplicateKeyReportsLine", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, ".env", "KEY=first\nOTHER=x\nKEY=second\n")
+		_, stderr := mustFailDir(t, dir, "from-env", "--name", "s", "--env-file", ".env")
+		assertContains(t, stderr, `line 3, column 1: duplicate key "KEY" (first set on line 1)`)
+	})
+
+	t.Run("RoundTripsExportEnv", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "cert.pem", "-----BEGIN CERT-----\nAAAA\n-----END CERT-----\n")
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--set", `PASS=pa$${HOME}"\'#x`, "--set-file", "CERT=cert.pem", "--output", "secret.yaml")
+		mustRunDir(t, dir, "export-env", "--input", "secret.yaml", "--output", ".env")
+		mustRunDir(t, dir, "from-env", "--name", "s",
+			"--env-file", ".env", "--output", "reimported.yaml")
+
+		assertEqual(t, showKey(t, dir, "reimported.yaml", "PASS"), `pa$${HOME}"\'#x`)
+		assertEqual(t, showKey(t, dir, "reimported.yaml", "CERT"), showKey(t, dir, "secret.yaml", "CERT"))
+	})
 }
 
 // ── export-env ────────────────────────────────────────────────────────────────
@@ -166,11 +282,215 @@ func TestExportEnv(t *testing.T) {
 
 		assertContains(t, out, `"value with spaces"`)
 	})
+
+	t.Run("BinaryRoundTrip", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "blob.bin", "\x00\x01\xfe\xff")
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--set-file", "BLOB=blob.bin", "--set", "TEXT=plain", "--output", "secret.yaml")
+		mustRunDir(t, dir, "export-env", "--input", "secret.yaml", "--output", ".env")
+		assertContains(t, readFile(t, dir, ".env"), "BLOB=!!binary AAH+/w==")
+
+		mustRunDir(t, dir, "from-env", "--name", "s",
+			"--env-file", ".env", "--output", "reimported.yaml")
+		assertEqual(t, readFile(t, dir, "reimported.yaml"), readFile(t, dir, "secret.yaml"))
+	})
+}
+
+// ── edit ──────────────────────────────────────────────────────────────────────
+
+// useEditor points $EDITOR at a script that saves the buffer it is given as
+// seen.yaml in dir and replaces it with the content of next.yaml, if any.
+// A then.yaml becomes next.yaml for the following editor session.
+func useEditor(t *testing.T, dir string) {
+	t.Helper()
+	script := writeFile(t, dir, "editor.sh", fmt.Sprintf("#!/bin/sh\n"+
+		"cp \"$1\" %[1]q/seen.yaml\n"+
+		"if [ -f %[1]q/next.yaml ]; then cp %[1]q/next.yaml \"$1\"; fi\n"+
+		"if [ -f %[1]q/then.yaml ]; then mv %[1]q/then.yaml %[1]q/next.yaml; fi\n"+
+		"if [ -f %[1]q/during.sh ]; then sh %[1]q/during.sh; rm %[1]q/during.sh; fi\n", dir))
+	if err := os.Chmod(script, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", script)
+}
+
+func TestEdit(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		writeFile(t, dir, "ks.bin", "\x00\x01\xfe\xff")
+		writeFile(t, dir, "ca.pem", "-----BEGIN CERT-----\nAAAA\n-----END CERT-----\n")
+		mustRunDir(t, dir, "generate", "--name", "s", "--set", "API_KEY=abc",
+			"--set-file", "KEYSTORE=ks.bin", "--set-file", "CA=ca.pem",
+			"--label", "app=web", "--output", "secret.yaml")
+		useEditor(t, dir)
+		return dir
+	}
+
+	t.Run("BufferFormat", func(t *testing.T) {
+		dir := setup(t)
+		before := readFile(t, dir, "secret.yaml")
+		_, stderr := mustRunDir(t, dir, "edit", "--input", "secret.yaml")
+		assertContains(t, stderr, "Edit cancelled, no changes made.")
+
+		seen := readFile(t, dir, "seen.yaml")
+		assertContains(t, seen, "type: Opaque")
+		assertContains(t, seen, "labels:\n  app: web")
+		assertContains(t, seen, "  CA: |\n    -----BEGIN CERT-----\n    AAAA\n")
+		assertContains(t, seen, "  KEYSTORE: !!binary AAH+/w==")
+		assertEqual(t, readFile(t, dir, "secret.yaml"), before)
+	})
+
+	t.Run("EditValuesAndMetadata", func(t *testing.T) {
+		dir := setup(t)
+		writeFile(t, dir, "next.yaml", `type: example.com/custom
+labels:
+  app: api
+annotations:
+  owner: team-a
+data:
+  API_KEY: newvalue123
+  CA: |
+    -----BEGIN CERT-----
+    BBBB
+    -----END CERT-----
+  KEYSTORE: !!binary AAH+/w==
+`)
+		_, stderr := mustRunDir(t, dir, "edit", "--input", "secret.yaml")
+		assertContains(t, stderr, "~ type: Opaque → example.com/custom")
+		assertContains(t, stderr, "~ API_KEY=<masked len=3")
+		assertContains(t, stderr, "~ CA=<masked")
+		assertNotContains(t, stderr, "KEYSTORE")
+		assertNotContains(t, stderr, "newvalue123")
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "API_KEY"), "newvalue123")
+		assertContains(t, showKey(t, dir, "secret.yaml", "CA"), "BBBB")
+		yaml := readFile(t, dir, "secret.yaml")
+		assertContains(t, yaml, "KEYSTORE: AAH+/w==")
+		assertContains(t, yaml, "type: example.com/custom")
+		assertContains(t, yaml, "owner: team-a")
+		assertContains(t, yaml, "app: api")
+	})
+
+	t.Run("BinaryReadOnly", func(t *testing.T) {
+		dir := setup(t)
+		before := readFile(t, dir, "secret.yaml")
+		writeFile(t, dir, "next.yaml", "data:\n  KEYSTORE: oops\n")
+		_, stderr := mustFailDir(t, dir, "edit", "--input", "secret.yaml")
+		assertContains(t, stderr, "no valid changes were saved")
+		assertContains(t, stderr, "KEYSTORE is binary and read-only")
+		assertEqual(t, readFile(t, dir, "secret.yaml"), before)
+	})
+
+	t.Run("ReopensOnError", func(t *testing.T) {
+		dir := setup(t)
+		writeFile(t, dir, "next.yaml", "data:\n  bad key: x\n  KEYSTORE: !!binary AAH+/w==\n")
+		writeFile(t, dir, "then.yaml", "data:\n  GOOD_KEY: x\n  KEYSTORE: !!binary AAH+/w==\n")
+		_, stderr := mustRunDir(t, dir, "edit", "--input", "secret.yaml")
+
+		// The second session saw the error and the first session's changes.
+		seen := readFile(t, dir, "seen.yaml")
+		assertContains(t, seen, "# ERROR: ")
+		assertContains(t, seen, "invalid characters")
+		assertContains(t, seen, "  bad key: x\n")
+
+		assertContains(t, stderr, "+ GOOD_KEY=<masked len=1")
+		assertContains(t, stderr, "- API_KEY")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "GOOD_KEY"), "x")
+	})
+
+	t.Run("ReopensOnInvalidLabel", func(t *testing.T) {
+		dir := setup(t)
+		writeFile(t, dir, "next.yaml", "labels:\n  bad label!: x\ndata:\n  A: b\n")
+		writeFile(t, dir, "then.yaml", "labels:\n  good: x\ndata:\n  A: b\n")
+		mustRunDir(t, dir, "edit", "--input", "secret.yaml")
+		assertContains(t, readFile(t, dir, "seen.yaml"), "# ERROR: secret default/s violates Kubernetes limits")
+		assertContains(t, readFile(t, dir, "secret.yaml"), "good: x")
+	})
+
+	t.Run("BinaryFromFile", func(t *testing.T) {
+		dir := setup(t)
+		writeFile(t, dir, "new.bin", "\xde\xad\xbe\xef")
+		writeFile(t, dir, "next.yaml", "data:\n  KEYSTORE: \"@new.bin\"\n")
+		mustRunDir(t, dir, "edit", "--input", "secret.yaml")
+		assertContains(t, readFile(t, dir, "secret.yaml"), "KEYSTORE: 3q2+7w==")
+	})
+
+	// during.sh runs inside the editor session, as a teammate would.
+	concurrently := func(t *testing.T, dir string, args ...string) {
+		t.Helper()
+		cmd := fmt.Sprintf("cd %q && %q", dir, binaryPath)
+		for _, a := range args {
+			cmd += fmt.Sprintf(" %q", a)
+		}
+		writeFile(t, dir, "during.sh", cmd+"\n")
+	}
+
+	t.Run("MergesConcurrentChange", func(t *testing.T) {
+		dir := setup(t)
+		concurrently(t, dir, "update", "--input", "secret.yaml", "--set", "TEAM=theirs", "--label", "tier=db")
+		writeFile(t, dir, "next.yaml", "labels:\n  app: web\ndata:\n  API_KEY: mine\n  KEYSTORE: !!binary AAH+/w==\n")
+		_, stderr := mustRunDir(t, dir, "edit", "--input", "secret.yaml")
+		assertContains(t, stderr, "Merged changes made to secret.yaml during the edit:")
+		assertContains(t, stderr, "+ TEAM=<masked len=6")
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "API_KEY"), "mine")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "TEAM"), "theirs")
+		yaml := readFile(t, dir, "secret.yaml")
+		assertContains(t, yaml, "tier: db")
+		assertNotContains(t, yaml, "CA:")
+	})
+
+	t.Run("RefusesConflictingChange", func(t *testing.T) {
+		dir := setup(t)
+		concurrently(t, dir, "update", "--input", "secret.yaml", "--set", "API_KEY=theirs")
+		writeFile(t, dir, "next.yaml", "labels:\n  app: web\ndata:\n  API_KEY: mine\n  KEYSTORE: !!binary AAH+/w==\n")
+		_, stderr := mustFailDir(t, dir, "edit", "--input", "secret.yaml")
+		assertContains(t, stderr, "secret.yaml changed while it was being edited")
+		assertContains(t, stderr, "data API_KEY: was <masked len=3")
+		assertContains(t, stderr, "nothing was written")
+		assertNotContains(t, stderr, "mine")
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "API_KEY"), "theirs")
+		assertContains(t, showKey(t, dir, "secret.yaml", "CA"), "BEGIN CERT")
+	})
 }
 
 // ── update ────────────────────────────────────────────────────────────────────
 
 func TestUpdate(t *testing.T) {
+	t.Run("InvalidLabelRejectedBeforeWrite", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+		before := readFile(t, dir, "secret.yaml")
+
+		_, stderr := mustFailDir(t, dir, "update", "--input", "secret.yaml", "--label", "bad key=v")
+		assertContains(t, stderr, "label key")
+		assertEqual(t, readFile(t, dir, "secret.yaml"), before)
+	})
+
+	t.Run("OversizedSecretRejected", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+		writeFile(t, dir, "big.bin", strings.Repeat("x", 1024*1024))
+
+		_, stderr := mustFailDir(t, dir, "update", "--input", "secret.yaml", "--set-file", "BIG=big.bin")
+		assertContains(t, stderr, "1 MiB")
+	})
+
+	t.Run("RejectedWriteKeepsBackup", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "v1", "secret.yaml")
+		mustRunDir(t, dir, "update", "--input", "secret.yaml", "--set", "KEY=v2", "--backup")
+		backup := readFile(t, dir, "secret.yaml.bak")
+		writeFile(t, dir, "big.bin", strings.Repeat("x", 1024*1024))
+
+		mustFailDir(t, dir, "update", "--input", "secret.yaml", "--set-file", "BIG=big.bin", "--backup")
+		assertEqual(t, readFile(t, dir, "secret.yaml.bak"), backup)
+		assertEqual(t, showKey(t, dir, "secret.yaml", "KEY"), "v2")
+	})
+
 	t.Run("OverwriteKey", func(t *testing.T) {
 		dir := t.TempDir()
 		generateBasic(t, dir, "s", "KEY", "original", "secret.yaml")
@@ -219,6 +539,15 @@ func TestUpdate(t *testing.T) {
 		assertContains(t, stderr, "invalid characters")
 	})
 
+	t.Run("BackupKeepsPreviousVersion", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "old", "secret.yaml")
+		mustRunDir(t, dir, "update", "--input", "secret.yaml", "--set", "KEY=new", "--backup")
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "KEY"), "new")
+		assertEqual(t, showKey(t, dir, "secret.yaml.bak", "KEY"), "old")
+	})
+
 	t.Run("DeleteMissingKeyErrors", func(t *testing.T) {
 		dir := t.TempDir()
 		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
@@ -294,6 +623,135 @@ func TestRotate(t *testing.T) {
 		_, stderr := mustRunDir(t, dir, "rotate", "--input", "secret.yaml",
 			"--key", "SECRET")
 		assertContains(t, stderr, "SECRET=")
+		assertContains(t, stderr, "191 bits of entropy")
+	})
+
+	t.Run("Keygen", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--keygen", "jwt.key=ed25519", "--public-key", "jwt.key=jwt.pub", "--output", "secret.yaml")
+		oldKey := showKey(t, dir, "secret.yaml", "jwt.key")
+		oldPub := showKey(t, dir, "secret.yaml", "jwt.pub")
+
+		mustRunDir(t, dir, "rotate", "--input", "secret.yaml",
+			"--keygen", "jwt.key=rsa:2048", "--public-key", "jwt.key=jwt.pub")
+		newKey := showKey(t, dir, "secret.yaml", "jwt.key")
+		if newKey == oldKey || showKey(t, dir, "secret.yaml", "jwt.pub") == oldPub {
+			t.Error("key pair was not regenerated")
+		}
+		assertContains(t, newKey, "BEGIN PRIVATE KEY")
+	})
+
+	t.Run("ReissueTLS", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "ca", "--tls-cn", "Dev CA", "--tls-is-ca", "--output", "ca.yaml")
+		mustRunDir(t, dir, "generate", "--name", "web", "--tls-cn", "web",
+			"--tls-san", "web.internal", "--tls-days", "30", "--tls-ca-secret", "ca.yaml",
+			"--tls-include-ca", "--output", "secret.yaml")
+		oldCert := showKey(t, dir, "secret.yaml", "tls.crt")
+
+		_, stderr := mustFailDir(t, dir, "rotate", "--input", "secret.yaml", "--reissue-tls")
+		assertContains(t, stderr, "was issued by")
+
+		_, stderr = mustRunDir(t, dir, "rotate", "--input", "secret.yaml", "--reissue-tls", "--tls-ca-secret", "ca.yaml")
+		assertContains(t, stderr, "1 SAN(s)")
+		if showKey(t, dir, "secret.yaml", "tls.crt") == oldCert {
+			t.Error("certificate was not reissued")
+		}
+		mustRunDir(t, dir, "validate", "--input", "secret.yaml")
+	})
+
+	t.Run("KeygenKeyNotFound", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "rotate", "--input", "secret.yaml", "--keygen", "MISSING=ed25519")
+		assertContains(t, stderr, "not found")
+	})
+
+	t.Run("PasswordPolicy", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "SECRET", "x", "secret.yaml")
+		mustRunDir(t, dir, "rotate", "--input", "secret.yaml", "--key", "SECRET",
+			"--charset", "printable", "--exclude-chars", `"'$`+"`\\",
+			"--length", "12", "--min-upper", "3", "--min-digits", "3", "--min-symbols", "3")
+
+		val := showKey(t, dir, "secret.yaml", "SECRET")
+		if len(val) != 12 || strings.ContainsAny(val, `"'$`+"`\\") {
+			t.Errorf("value violates policy: %q", val)
+		}
+	})
+
+	t.Run("UnsatisfiablePolicy", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "SECRET", "x", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "rotate", "--input", "secret.yaml", "--key", "SECRET",
+			"--min-symbols", "1")
+		assertContains(t, stderr, "symbol")
+	})
+
+	t.Run("Passphrase", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "SECRET", "x", "secret.yaml")
+		_, stderr := mustRunDir(t, dir, "rotate", "--input", "secret.yaml", "--key", "SECRET",
+			"--words", "5", "--word-separator", " ")
+		assertContains(t, stderr, "65 bits of entropy")
+
+		if words := strings.Fields(showKey(t, dir, "secret.yaml", "SECRET")); len(words) != 5 {
+			t.Errorf("expected 5 words, got %q", words)
+		}
+	})
+	t.Run("Entries", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--entry", "alice:pass1", "--entry", "bob:pass2", "--entry", "carol:pass3",
+			"--output", "secret.yaml")
+		_, stderr := mustRunDir(t, dir, "rotate", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--entry", "bob")
+		assertContains(t, stderr, "PASSES[bob]=")
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "alice;bob;carol")
+		vals := strings.Split(showKey(t, dir, "secret.yaml", "PASSES"), ";")
+		if len(vals) != 3 || vals[0] != "pass1" || vals[2] != "pass3" || vals[1] == "pass2" || len(vals[1]) != 32 {
+			t.Errorf("unexpected values after rotating bob: %q", vals)
+		}
+	})
+
+	t.Run("AllEntries", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--entry", "alice:pass1", "--entry", "bob:pass2", "--output", "secret.yaml")
+		mustRunDir(t, dir, "rotate", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--all-entries", "--length", "16")
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "alice;bob")
+		for _, v := range strings.Split(showKey(t, dir, "secret.yaml", "PASSES"), ";") {
+			if len(v) != 16 || strings.HasPrefix(v, "pass") {
+				t.Errorf("value not rotated: %q", v)
+			}
+		}
+	})
+
+	t.Run("EntryNotFound", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--entry", "alice:pass1", "--output", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "rotate", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--entry", "dave")
+		assertContains(t, stderr, `entry "dave" not found`)
+	})
+
+	t.Run("EntryValueWithSeparator", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--entry", "alice:pass1", "--output", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "rotate", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--entry", "alice",
+			"--custom-charset", ";")
+		assertContains(t, stderr, "separator")
 	})
 }
 
@@ -327,6 +785,40 @@ func TestAddEntry(t *testing.T) {
 		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "alice;bob;charlie")
 	})
 
+	t.Run("ValueWithSeparatorRejected", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--entry", "alice:pass1", "--output", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "add-entry", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--key", "bob", "--value", "p;ss")
+		assertContains(t, stderr, "contains the separator")
+		assertContains(t, stderr, "--quote")
+		assertNotContains(t, stderr, "p;ss")
+	})
+
+	t.Run("QuotedValues", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--entry", "alice:pass1", "--output", "secret.yaml")
+		for _, e := range [][2]string{{"bob", "p;ss"}, {"carol", ""}, {"dave", " padded "}} {
+			mustRunDir(t, dir, "add-entry", "--input", "secret.yaml",
+				"--entries-key", "USERS", "--entries-val", "PASSES", "--quote", `"`,
+				"--key", e[0], "--value", e[1])
+		}
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PASSES"), `pass1;"p;ss";"";" padded "`)
+
+		// Reading the lists with the same --quote keeps every pairing.
+		mustRunDir(t, dir, "remove-entry", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--quote", `"`, "--key", "bob")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "alice;carol;dave")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PASSES"), `pass1;"";" padded "`)
+		mustRunDir(t, dir, "list-entries", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--quote", `"`)
+	})
+
 	t.Run("DuplicateKeyErrors", func(t *testing.T) {
 		dir := t.TempDir()
 		mustRunDir(t, dir, "generate", "--name", "s",
@@ -379,6 +871,193 @@ func TestRemoveEntry(t *testing.T) {
 	})
 }
 
+func TestEditEntries(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--entry", "carol:pass3", "--entry", "alice:pass1", "--entry", "bob:pass2",
+			"--output", "secret.yaml")
+		return dir
+	}
+	lists := []string{"--entries-key", "USERS", "--entries-val", "PASSES"}
+
+	t.Run("UpdateKeepsPosition", func(t *testing.T) {
+		dir := setup(t)
+		mustRunDir(t, dir, append([]string{"update-entry", "--input", "secret.yaml",
+			"--key", "alice", "--value", "newpass"}, lists...)...)
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "carol;alice;bob")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PASSES"), "pass3;newpass;pass2")
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		dir := setup(t)
+		mustRunDir(t, dir, append([]string{"rename-entry", "--input", "secret.yaml",
+			"--key", "alice", "--new-key", "alicia"}, lists...)...)
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "carol;alicia;bob")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PASSES"), "pass3;pass1;pass2")
+
+		_, stderr := mustFailDir(t, dir, append([]string{"rename-entry", "--input", "secret.yaml",
+			"--key", "alicia", "--new-key", "bob"}, lists...)...)
+		assertContains(t, stderr, "already exists")
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		dir := setup(t)
+		mustRunDir(t, dir, append([]string{"move-entry", "--input", "secret.yaml",
+			"--key", "bob", "--index", "0"}, lists...)...)
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "bob;carol;alice")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PASSES"), "pass2;pass3;pass1")
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		dir := setup(t)
+		mustRunDir(t, dir, append([]string{"move-entry", "--input", "secret.yaml", "--sort"}, lists...)...)
+
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "alice;bob;carol")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PASSES"), "pass1;pass2;pass3")
+	})
+
+	t.Run("MoveRequiresIndex", func(t *testing.T) {
+		dir := setup(t)
+		_, stderr := mustFailDir(t, dir, append([]string{"move-entry", "--input", "secret.yaml",
+			"--key", "bob"}, lists...)...)
+		assertContains(t, stderr, "--index")
+	})
+
+	t.Run("UpdateMissingEntry", func(t *testing.T) {
+		dir := setup(t)
+		_, stderr := mustFailDir(t, dir, append([]string{"update-entry", "--input", "secret.yaml",
+			"--key", "dave", "--value", "x"}, lists...)...)
+		assertContains(t, stderr, "not found")
+	})
+}
+
+func TestEntryTable(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--entries-val", "DBS",
+			"--entry", "alice:pass1:orders", "--entry", `bob:pass2:postgres\://db\:5432/app`,
+			"--output", "secret.yaml")
+		return dir
+	}
+	lists := []string{"--entries-key", "USERS", "--entries-val", "PASSES", "--entries-val", "DBS"}
+
+	t.Run("Generate", func(t *testing.T) {
+		dir := setup(t)
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "alice;bob")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PASSES"), "pass1;pass2")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "DBS"), "orders;postgres://db:5432/app")
+	})
+
+	t.Run("GenerateMissingColumn", func(t *testing.T) {
+		dir := t.TempDir()
+		_, stderr := mustFailDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--entries-val", "DBS",
+			"--entry", "alice:pass1")
+		assertContains(t, stderr, "key:value:value")
+	})
+
+	t.Run("GenerateAmbiguousColon", func(t *testing.T) {
+		dir := t.TempDir()
+		_, stderr := mustFailDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--entries-val", "DBS",
+			"--entry", "alice:p:ss:orders")
+		assertContains(t, stderr, `write a colon inside a value as \:`)
+	})
+
+	t.Run("AddRemoveMove", func(t *testing.T) {
+		dir := setup(t)
+		mustRunDir(t, dir, append([]string{"add-entry", "--input", "secret.yaml",
+			"--key", "carol", "--value", "pass3", "--value", "reporting", "--index", "0"}, lists...)...)
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "carol;alice;bob")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "DBS"), "reporting;orders;postgres://db:5432/app")
+
+		mustRunDir(t, dir, append([]string{"remove-entry", "--input", "secret.yaml",
+			"--key", "alice"}, lists...)...)
+		mustRunDir(t, dir, append([]string{"move-entry", "--input", "secret.yaml", "--sort"}, lists...)...)
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "bob;carol")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PASSES"), "pass2;pass3")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "DBS"), "postgres://db:5432/app;reporting")
+	})
+
+	t.Run("ValueCountMismatch", func(t *testing.T) {
+		dir := setup(t)
+		_, stderr := mustFailDir(t, dir, append([]string{"add-entry", "--input", "secret.yaml",
+			"--key", "carol", "--value", "pass3"}, lists...)...)
+		assertContains(t, stderr, "--value given 1 time(s) for 2 --entries-val column(s)")
+	})
+
+	t.Run("ColumnLengthMismatch", func(t *testing.T) {
+		dir := setup(t)
+		mustRunDir(t, dir, "update", "--input", "secret.yaml", "--set", "DBS=orders")
+		_, stderr := mustFailDir(t, dir, append([]string{"remove-entry", "--input", "secret.yaml",
+			"--key", "alice"}, lists...)...)
+		assertContains(t, stderr, "column DBS has 1 item(s)")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "alice;bob")
+	})
+
+	t.Run("UpdateRename", func(t *testing.T) {
+		dir := setup(t)
+		mustRunDir(t, dir, append([]string{"update-entry", "--input", "secret.yaml",
+			"--key", "alice", "--value", "newpass", "--value", "billing"}, lists...)...)
+		mustRunDir(t, dir, append([]string{"rename-entry", "--input", "secret.yaml",
+			"--key", "bob", "--new-key", "robert"}, lists...)...)
+		assertEqual(t, showKey(t, dir, "secret.yaml", "USERS"), "alice;robert")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "PASSES"), "newpass;pass2")
+		assertEqual(t, showKey(t, dir, "secret.yaml", "DBS"), "billing;postgres://db:5432/app")
+
+		_, stderr := mustFailDir(t, dir, append([]string{"update-entry", "--input", "secret.yaml",
+			"--key", "alice", "--value", "x"}, lists...)...)
+		assertContains(t, stderr, "--value given 1 time(s) for 2 --entries-val column(s)")
+	})
+}
+
+func TestListEntries(t *testing.T) {
+	t.Run("MaskedTable", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--entry", "alice:pass1", "--entry", "bob:pass2", "--output", "secret.yaml")
+		out, _ := mustRunDir(t, dir, "list-entries", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--reveal-entry", "bob")
+		assertContains(t, out, "alice")
+		assertNotContains(t, out, "pass1")
+		assertContains(t, out, "pass2")
+
+		_, stderr := mustFailDir(t, dir, "list-entries", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--reveal-key", "bob")
+		assertContains(t, stderr, "unknown flag: --reveal-key")
+	})
+
+	t.Run("JSONReportsProblems", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--set", "USERS=alice;bob;alice;carol", "--set", "PASSES=pass1;;pass3", "--output", "secret.yaml")
+		out, stderr := mustFailDir(t, dir, "--output-format", "json", "list-entries", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES")
+		assertContains(t, out, `"kind": "length-mismatch"`)
+		assertContains(t, out, `"kind": "duplicate-key"`)
+		assertContains(t, out, `"kind": "empty-value"`)
+		assertContains(t, out, `"missing": "value"`)
+		assertContains(t, stderr, "3 problem(s)")
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "USERS", "alice", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "list-entries", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES")
+		assertContains(t, stderr, `"PASSES" not found`)
+	})
+}
+
 // ── show / list ───────────────────────────────────────────────────────────────
 
 func TestShow(t *testing.T) {
@@ -394,12 +1073,29 @@ func TestShow(t *testing.T) {
 		dir := t.TempDir()
 		mustRunDir(t, dir, "generate", "--name", "s",
 			"--set", "AAA=1", "--set", "BBB=2", "--output", "secret.yaml")
-		out, _ := mustRunDir(t, dir, "show", "--input", "secret.yaml")
+		out, _ := mustRunDir(t, dir, "show", "--input", "secret.yaml", "--reveal")
 
 		assertContains(t, out, "AAA: 1")
 		assertContains(t, out, "BBB: 2")
 	})
 
+	t.Run("MaskedByDefault", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "API_KEY", "secret-value", "secret.yaml")
+		out, _ := mustRunDir(t, dir, "show", "--input", "secret.yaml")
+		assertNotContains(t, out, "secret-value")
+		assertContains(t, out, "API_KEY: <masked len=12 sha256:31160254>")
+	})
+
+	t.Run("RevealKey", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--set", "AAA=visible", "--set", "BBB=hidden", "--output", "secret.yaml")
+		out, _ := mustRunDir(t, dir, "show", "--input", "secret.yaml", "--reveal-key", "AAA")
+		assertContains(t, out, "AAA: visible")
+		assertNotContains(t, out, "hidden")
+	})
+
 	t.Run("MissingKeyErrors", func(t *testing.T) {
 		dir := t.TempDir()
 		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
@@ -436,7 +1132,7 @@ func TestDiff(t *testing.T) {
 		generateBasic(t, dir, "s", "KEY", "val", "a.yaml")
 		mustRunDir(t, dir, "generate", "--name", "s",
 			"--set", "KEY=val", "--set", "NEW=added", "--output", "b.yaml")
-		out, _ := mustRunDir(t, dir, "diff", "--from", "a.yaml", "--to", "b.yaml")
+		out, _ := mustRunDir(t, dir, "diff", "--from", "a.yaml", "--to", "b.yaml", "--reveal")
 		assertContains(t, out, "+ NEW=added")
 	})
 
@@ -444,21 +1140,111 @@ func TestDiff(t *testing.T) {
 		dir := t.TempDir()
 		generateBasic(t, dir, "s", "KEY", "old", "a.yaml")
 		generateBasic(t, dir, "s", "KEY", "new", "b.yaml")
-		out, _ := mustRunDir(t, dir, "diff", "--from", "a.yaml", "--to", "b.yaml")
+		out, _ := mustRunDir(t, dir, "diff", "--from", "a.yaml", "--to", "b.yaml", "--reveal")
 		assertContains(t, out, "- KEY=old")
 		assertContains(t, out, "+ KEY=new")
 	})
 
+	t.Run("MaskedByDefault", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "old", "a.yaml")
+		generateBasic(t, dir, "s", "KEY", "new", "b.yaml")
+		out, _ := mustRunDir(t, dir, "diff", "--from", "a.yaml", "--to", "b.yaml")
+		assertNotContains(t, out, "old")
+		assertNotContains(t, out, "new")
+		assertContains(t, out, "- KEY=<masked len=3 sha256:")
+		assertContains(t, out, "+ KEY=<masked len=3 sha256:")
+	})
+
 	t.Run("RemovedKey", func(t *testing.T) {
 		dir := t.TempDir()
 		mustRunDir(t, dir, "generate", "--name", "s",
 			"--set", "KEY=val", "--set", "OLD=removed", "--output", "a.yaml")
 		generateBasic(t, dir, "s", "KEY", "val", "b.yaml")
-		out, _ := mustRunDir(t, dir, "diff", "--from", "a.yaml", "--to", "b.yaml")
+		out, _ := mustRunDir(t, dir, "diff", "--from", "a.yaml", "--to", "b.yaml", "--reveal")
 		assertContains(t, out, "- OLD=removed")
 	})
 }
 
+// ── --output-format ──────────────────────────────────────────────────────────
+
+func TestOutputFormat(t *testing.T) {
+	t.Run("ListJSON", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "value", "secret.yaml")
+		out, _ := mustRunDir(t, dir, "--output-format", "json", "list", "--input", "secret.yaml")
+
+		var doc struct {
+			Secrets []struct {
+				Name string `json:"name"`
+				Keys []struct {
+					Name string `json:"name"`
+					Size int    `json:"size"`
+				} `json:"keys"`
+			} `json:"secrets"`
+		}
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		if len(doc.Secrets) != 1 || doc.Secrets[0].Name != "s" ||
+			len(doc.Secrets[0].Keys) != 1 || doc.Secrets[0].Keys[0].Size != 5 {
+			t.Errorf("unexpected list document: %s", out)
+		}
+	})
+
+	t.Run("ShowJSONMasked", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "value", "secret.yaml")
+		out, _ := mustRunDir(t, dir, "show", "--input", "secret.yaml", "--output-format", "json")
+		assertContains(t, out, `"sha256"`)
+		assertNotContains(t, out, `"value"`)
+
+		out, _ = mustRunDir(t, dir, "show", "--input", "secret.yaml", "--output-format", "json", "--reveal")
+		assertContains(t, out, `"value": "value"`)
+	})
+
+	t.Run("DiffYAML", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "old", "a.yaml")
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--set", "KEY=new", "--set", "ADDED=x", "--output", "b.yaml")
+		out, _ := mustRunDir(t, dir, "diff", "--from", "a.yaml", "--to", "b.yaml", "--output-format", "yaml")
+		assertContains(t, out, "kind: added")
+		assertContains(t, out, "kind: changed")
+		assertContains(t, out, "key: ADDED")
+	})
+
+	t.Run("ValidateJSON", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "bad", "--type", "kubernetes.io/tls",
+			"--set", "K=v", "--output", "bad.yaml")
+		out, _ := mustFailDir(t, dir, "validate", "--input", "bad.yaml", "--output-format", "json")
+
+		var doc struct {
+			Valid  bool `json:"valid"`
+			Errors int  `json:"errors"`
+			Issues []struct {
+				Severity string `json:"severity"`
+				Code     string `json:"code"`
+				Key      string `json:"key"`
+			} `json:"issues"`
+		}
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		if doc.Valid || doc.Errors != 2 || doc.Issues[0].Code != "required-key-missing" || doc.Issues[0].Key != "tls.crt" {
+			t.Errorf("unexpected validation document: %s", out)
+		}
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "v", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "list", "--input", "secret.yaml", "--output-format", "xml")
+		assertContains(t, stderr, "--output-format")
+	})
+}
+
 // ── copy ──────────────────────────────────────────────────────────────────────
 
 func TestCopy(t *testing.T) {
@@ -514,6 +1300,43 @@ func TestValidate(t *testing.T) {
 		assertContains(t, stderr, "tls.crt")
 	})
 
+	t.Run("TLSKeyMismatch", func(t *testing.T) {
+		dir := t.TempDir()
+		writeSealingKeyPair(t, dir, "tls.crt", "")
+		writeSealingKeyPair(t, dir, "other.crt", "tls.key")
+		mustRunDir(t, dir, "generate", "--name", "tls",
+			"--tls-cert", "tls.crt", "--tls-key", "tls.key", "--output", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "validate", "--input", "secret.yaml")
+		assertContains(t, stderr, "does not match")
+	})
+
+	t.Run("TLSExpiryWindow", func(t *testing.T) {
+		dir := t.TempDir()
+		// writeSealingKeyPair certificates expire in one hour.
+		writeSealingKeyPair(t, dir, "tls.crt", "tls.key")
+		mustRunDir(t, dir, "generate", "--name", "tls",
+			"--tls-cert", "tls.crt", "--tls-key", "tls.key", "--output", "secret.yaml")
+		_, stderr := mustRunDir(t, dir, "validate", "--input", "secret.yaml")
+		assertContains(t, stderr, "expires on")
+
+		_, stderr = mustRunDir(t, dir, "validate", "--input", "secret.yaml", "--cert-expiry-days", "0")
+		assertNotContains(t, stderr, "expires on")
+	})
+
+	t.Run("StringDataOnly", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "secret.yaml", `apiVersion: v1
+kind: Secret
+metadata:
+  name: s
+  namespace: default
+stringData:
+  KEY: val
+`)
+		_, stderr := mustRunDir(t, dir, "validate", "--input", "secret.yaml")
+		assertNotContains(t, stderr, "no data keys")
+	})
+
 	t.Run("EmptyDataWarning", func(t *testing.T) {
 		dir := t.TempDir()
 		// An Opaque secret with no data keys produces a warning but exits 0.
@@ -523,6 +1346,84 @@ func TestValidate(t *testing.T) {
 	})
 }
 
+// ── policy ───────────────────────────────────────────────────────────────────
+
+const testPolicy = `rules:
+  - id: prod-owner
+    match:
+      namespaces: ["prod*"]
+    requireLabels: ["owner"]
+  - id: no-debug-keys
+    severity: warning
+    forbiddenKeys: ["DEBUG_*"]
+`
+
+func TestPolicy(t *testing.T) {
+	t.Run("ValidateReportsRuleIDs", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "policy.yaml", testPolicy)
+		mustRunDir(t, dir, "--namespace", "prod", "generate", "--name", "s",
+			"--set", "DEBUG_TOKEN=x", "--output", "secret.yaml")
+
+		out, _ := mustFailDir(t, dir, "--output-format", "json",
+			"validate", "--input", "secret.yaml", "--policy", "policy.yaml")
+		assertContains(t, out, `"code": "prod-owner"`)
+		assertContains(t, out, `"code": "no-debug-keys"`)
+		assertContains(t, out, `"key": "DEBUG_TOKEN"`)
+	})
+
+	t.Run("ScopedByNamespace", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "policy.yaml", testPolicy)
+		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+		_, stderr := mustRunDir(t, dir, "validate", "--input", "secret.yaml", "--policy", "policy.yaml")
+		assertNotContains(t, stderr, "prod-owner")
+	})
+
+	t.Run("GenerateBlocked", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "policy.yaml", testPolicy)
+		_, stderr := mustFailDir(t, dir, "--namespace", "prod", "generate", "--name", "s",
+			"--set", "KEY=val", "--policy", "policy.yaml", "--output", "secret.yaml")
+		assertContains(t, stderr, "violates policy")
+		if _, err := os.Stat(filepath.Join(dir, "secret.yaml")); !os.IsNotExist(err) {
+			t.Error("secret.yaml should not have been written")
+		}
+
+		mustRunDir(t, dir, "--namespace", "prod", "generate", "--name", "s",
+			"--set", "KEY=val", "--label", "owner=team-a", "--policy", "policy.yaml", "--output", "secret.yaml")
+	})
+
+	t.Run("UpdateBlockedWarningsAllowed", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "policy.yaml", testPolicy)
+		mustRunDir(t, dir, "--namespace", "prod", "generate", "--name", "s",
+			"--set", "KEY=val", "--label", "owner=team-a", "--output", "secret.yaml")
+		before := readFile(t, dir, "secret.yaml")
+
+		_, stderr := mustRunDir(t, dir, "update", "--input", "secret.yaml",
+			"--set", "DEBUG_TOKEN=x", "--policy", "policy.yaml")
+		assertContains(t, stderr, "warning: policy no-debug-keys")
+
+		writeFile(t, dir, "secret.yaml", before)
+		writeFile(t, dir, "strict.yaml", `rules:
+  - id: no-debug-keys
+    forbiddenKeys: ["DEBUG_*"]
+`)
+		mustFailDir(t, dir, "update", "--input", "secret.yaml",
+			"--set", "DEBUG_TOKEN=x", "--policy", "strict.yaml")
+		assertEqual(t, readFile(t, dir, "secret.yaml"), before)
+	})
+
+	t.Run("InvalidPolicy", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "policy.yaml", "rules:\n  - id: r\n    requireLabel: [owner]\n")
+		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "validate", "--input", "secret.yaml", "--policy", "policy.yaml")
+		assertContains(t, stderr, "requireLabel")
+	})
+}
+
 // ── seal (no kubeseal binary required) ───────────────────────────────────────
 
 func TestSeal_NoBinary(t *testing.T) {
@@ -534,3 +1435,306 @@ func TestSeal_NoBinary(t *testing.T) {
 		"seal", "--input", "secret.yaml")
 	assertContains(t, stderr, "kubeseal-does-not-exist")
 }
+
+func TestSeal_NativeWithCert(t *testing.T) {
+	dir := t.TempDir()
+	generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+	writeSealingKeyPair(t, dir, "pub-cert.pem", "")
+
+	mustRunDir(t, dir, "seal", "--input", "secret.yaml",
+		"--cert", "pub-cert.pem", "--output", "sealed.yaml")
+
+	sealed := readFile(t, dir, "sealed.yaml")
+	assertContains(t, sealed, "kind: SealedSecret")
+	assertContains(t, sealed, "encryptedData:")
+	assertContains(t, sealed, "KEY:")
+	assertNotContains(t, sealed, "dmFs") // base64("val")
+}
+
+func TestSeal_NativeClusterWide(t *testing.T) {
+	dir := t.TempDir()
+	generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+	writeSealingKeyPair(t, dir, "pub-cert.pem", "")
+
+	out, _ := mustRunDir(t, dir, "seal", "--input", "secret.yaml",
+		"--cert", "pub-cert.pem", "--scope", "cluster-wide")
+	assertContains(t, out, "sealedsecrets.bitnami.com/cluster-wide")
+}
+
+// ── unseal ───────────────────────────────────────────────────────────────────
+
+func TestUnseal_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+	writeSealingKeyPair(t, dir, "pub-cert.pem", "priv-key.pem")
+
+	mustRunDir(t, dir, "seal", "--input", "secret.yaml",
+		"--cert", "pub-cert.pem", "--output", "sealed.yaml")
+	mustRunDir(t, dir, "unseal", "--input", "sealed.yaml",
+		"--private-key", "priv-key.pem", "--output", "plain.yaml")
+
+	assertEqual(t, showKey(t, dir, "plain.yaml", "KEY"), "val")
+	mustRunDir(t, dir, "diff", "--from", "secret.yaml", "--to", "plain.yaml")
+}
+
+func TestUnseal_WrongKey(t *testing.T) {
+	dir := t.TempDir()
+	generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+	writeSealingKeyPair(t, dir, "pub-cert.pem", "priv-key.pem")
+	writeSealingKeyPair(t, dir, "other-cert.pem", "other-key.pem")
+
+	mustRunDir(t, dir, "seal", "--input", "secret.yaml",
+		"--cert", "pub-cert.pem", "--output", "sealed.yaml")
+	_, stderr := mustFailDir(t, dir, "unseal", "--input", "sealed.yaml",
+		"--private-key", "other-key.pem")
+	assertContains(t, stderr, "KEY")
+}
+
+// ── SOPS/age ─────────────────────────────────────────────────────────────────
+
+func TestSops(t *testing.T) {
+	t.Run("EncryptDecryptRoundTrip", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+		recipient := writeAgeIdentity(t, dir, "keys.txt")
+
+		mustRunDir(t, dir, "encrypt", "--input", "secret.yaml",
+			"--age-recipient", recipient, "--output", "secret.enc.yaml")
+		enc := readFile(t, dir, "secret.enc.yaml")
+		assertContains(t, enc, "ENC[AES256_GCM,")
+		assertContains(t, enc, "encrypted_regex: ^(data|stringData)$")
+		assertContains(t, enc, "name: s")
+		assertNotContains(t, enc, "dmFs")
+
+		mustRunDir(t, dir, "decrypt", "--input", "secret.enc.yaml",
+			"--age-identity", "keys.txt", "--output", "plain.yaml")
+		mustRunDir(t, dir, "diff", "--from", "secret.yaml", "--to", "plain.yaml")
+	})
+
+	t.Run("TransparentShowAndUpdate", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+		recipient := writeAgeIdentity(t, dir, "keys.txt")
+		mustRunDir(t, dir, "encrypt", "--input", "secret.yaml",
+			"--age-recipient", recipient, "--output", "secret.enc.yaml")
+
+		_, stderr := mustFailDir(t, dir, "show", "--input", "secret.enc.yaml")
+		assertContains(t, stderr, "--age-identity")
+
+		mustRunDir(t, dir, "update", "--input", "secret.enc.yaml",
+			"--age-identity", "keys.txt", "--set", "KEY=new")
+		assertContains(t, readFile(t, dir, "secret.enc.yaml"), "ENC[AES256_GCM,")
+
+		out, _ := mustRunDir(t, dir, "show", "--input", "secret.enc.yaml",
+			"--age-identity", "keys.txt", "--key", "KEY")
+		assertEqual(t, strings.TrimSpace(out), "new")
+
+		out, _ = mustRunDir(t, dir, "diff", "--from", "secret.yaml",
+			"--to", "secret.enc.yaml", "--age-identity", "keys.txt", "--reveal")
+		assertContains(t, out, "+ KEY=new")
+	})
+
+	t.Run("TamperedFileRejected", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "KEY", "val", "secret.yaml")
+		recipient := writeAgeIdentity(t, dir, "keys.txt")
+		mustRunDir(t, dir, "encrypt", "--input", "secret.yaml",
+			"--age-recipient", recipient, "--output", "secret.enc.yaml")
+
+		enc := readFile(t, dir, "secret.enc.yaml")
+		writeFile(t, dir, "secret.enc.yaml", strings.Replace(enc, "name: s", "name: x", 1))
+		_, stderr := mustFailDir(t, dir, "decrypt", "--input", "secret.enc.yaml",
+			"--age-identity", "keys.txt")
+		assertContains(t, stderr, "MAC")
+	})
+}
+
+// ── multi-document streams and directories ───────────────────────────────────
+
+const twoSecretStream = `apiVersion: v1
+kind: Secret
+metadata:
+  name: a
+  namespace: default
+stringData:
+  KEY_A: one
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: b
+  namespace: default
+stringData:
+  KEY_B: two
+`
+
+func TestMultiDocument(t *testing.T) {
+	t.Run("ListStream", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "secrets.yaml", twoSecretStream)
+		out, _ := mustRunDir(t, dir, "list", "--input", "secrets.yaml")
+		assertContains(t, out, "default/a")
+		assertContains(t, out, "default/b")
+		assertNotContains(t, out, "cfg")
+	})
+
+	t.Run("ShowKeyNeedsSelection", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "secrets.yaml", twoSecretStream)
+		_, stderr := mustFailDir(t, dir, "show", "--input", "secrets.yaml", "--key", "KEY_B")
+		assertContains(t, stderr, "--name")
+
+		out, _ := mustRunDir(t, dir, "show", "--input", "secrets.yaml", "--name", "b", "--key", "KEY_B")
+		assertEqual(t, strings.TrimSpace(out), "two")
+	})
+
+	t.Run("UpdateOneSecretInStream", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "secrets.yaml", twoSecretStream)
+		_, stderr := mustFailDir(t, dir, "update", "--input", "secrets.yaml", "--set", "NEW=x")
+		assertContains(t, stderr, "2 Secrets match")
+		assertEqual(t, readFile(t, dir, "secrets.yaml"), twoSecretStream)
+
+		mustRunDir(t, dir, "update", "--input", "secrets.yaml", "--name", "b", "--set", "NEW=x")
+		mustRunDir(t, dir, "rotate", "--input", "secrets.yaml", "--name", "b", "--key", "KEY_B", "--stamp")
+		mustRunDir(t, dir, "add-entry", "--input", "secrets.yaml", "--name", "b",
+			"--entries-key", "USERS", "--entries-val", "PASSES", "--key", "alice", "--value", "pass1")
+
+		out, _ := mustRunDir(t, dir, "show", "--input", "secrets.yaml", "--name", "b", "--key", "NEW")
+		assertEqual(t, strings.TrimSpace(out), "x")
+		out, _ = mustRunDir(t, dir, "show", "--input", "secrets.yaml", "--name", "b", "--key", "USERS")
+		assertEqual(t, strings.TrimSpace(out), "alice")
+
+		// Secret a and the ConfigMap are kept byte for byte.
+		stream := readFile(t, dir, "secrets.yaml")
+		assertContains(t, stream, "stringData:\n  KEY_A: one\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n---\n")
+		assertContains(t, stream, "last-rotated.KEY_B")
+	})
+
+	t.Run("EditOneSecretInStream", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "secrets.yaml", twoSecretStream)
+		useEditor(t, dir)
+		writeFile(t, dir, "next.yaml", "data:\n  KEY_A: edited\n")
+		mustRunDir(t, dir, "edit", "--input", "secrets.yaml", "--name", "a")
+		assertContains(t, readFile(t, dir, "seen.yaml"), "KEY_A: one")
+
+		out, _ := mustRunDir(t, dir, "show", "--input", "secrets.yaml", "--name", "a", "--key", "KEY_A")
+		assertEqual(t, strings.TrimSpace(out), "edited")
+		assertContains(t, readFile(t, dir, "secrets.yaml"), "name: b\n  namespace: default\nstringData:\n  KEY_B: two\n")
+	})
+
+	t.Run("ListDirectory", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "one", "K", "v", "one.yaml")
+		generateBasic(t, dir, "two", "K", "v", "two.yml")
+		writeFile(t, dir, "notes.txt", "ignored")
+		out, _ := mustRunDir(t, dir, "list", "--input", ".")
+		assertContains(t, out, "default/one")
+		assertContains(t, out, "default/two")
+	})
+
+	t.Run("ValidateGlob", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "good", "K", "v", "good.yaml")
+		mustRunDir(t, dir, "generate", "--name", "bad", "--type", "kubernetes.io/tls",
+			"--set", "K=v", "--output", "bad.yaml")
+		_, stderr := mustFailDir(t, dir, "validate", "--input", "*.yaml")
+		assertContains(t, stderr, "default/bad")
+		assertContains(t, stderr, "tls.crt")
+	})
+
+	t.Run("DiffDirectories", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s", "--set", "K=old", "--output", "base.yaml")
+		mustRunDir(t, dir, "generate", "--name", "s", "--set", "K=new", "--output", "next.yaml")
+		generateBasic(t, dir, "extra", "K", "v", "extra.yaml")
+
+		out, _ := mustRunDir(t, dir, "diff", "--from", "base.yaml", "--to", "[ne]*.yaml", "--reveal")
+		assertContains(t, out, "+ Secret default/extra")
+		assertContains(t, out, "- K=old")
+		assertContains(t, out, "+ K=new")
+	})
+}
+
+// ── stale ────────────────────────────────────────────────────────────────────
+
+func TestStale(t *testing.T) {
+	t.Run("RotateStampsKeys", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "SECRET", "x", "secret.yaml")
+		mustRunDir(t, dir, "rotate", "--input", "secret.yaml", "--key", "SECRET", "--max-age", "90d")
+		mustRunDir(t, dir, "rotate", "--input", "secret.yaml", "--key", "SECRET", "--stamp")
+
+		yaml := readFile(t, dir, "secret.yaml")
+		assertContains(t, yaml, "k8s-secret-manifest.io/last-rotated.SECRET")
+		assertContains(t, yaml, `k8s-secret-manifest.io/rotation-count.SECRET: "2"`)
+		assertContains(t, yaml, "k8s-secret-manifest.io/max-age.SECRET: 90d")
+
+		out, _ := mustRunDir(t, dir, "--output-format", "json", "stale", "--input", "secret.yaml", "--all")
+		assertContains(t, out, `"stale": 0`)
+		assertContains(t, out, `"rotationCount": 2`)
+	})
+
+	t.Run("ReportsExpiredKeys", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "old.yaml", `apiVersion: v1
+kind: Secret
+metadata:
+  name: old
+  namespace: default
+  annotations:
+    k8s-secret-manifest.io/last-rotated.DB_PASS: "2020-01-01T00:00:00Z"
+    k8s-secret-manifest.io/rotation-count.DB_PASS: "3"
+    k8s-secret-manifest.io/max-age.DB_PASS: 30d
+data:
+  DB_PASS: eA==
+  OTHER: eA==
+`)
+		generateBasic(t, dir, "fresh", "KEY", "val", "fresh.yaml")
+
+		out, stderr := mustFailDir(t, dir, "stale", "--input", ".")
+		assertContains(t, out, "STALE")
+		assertContains(t, out, "DB_PASS")
+		assertNotContains(t, out, "OTHER")
+		assertContains(t, stderr, "1 stale key(s)")
+
+		// A default maximum age also covers keys that were never stamped.
+		out, _ = mustFailDir(t, dir, "stale", "--input", ".", "--default-max-age", "365d")
+		assertContains(t, out, "never rotated")
+		assertContains(t, out, "KEY")
+	})
+
+	t.Run("UpdateForgetsDeletedKey", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "A", "x", "secret.yaml")
+		mustRunDir(t, dir, "update", "--input", "secret.yaml", "--set", "B=y", "--stamp")
+		assertContains(t, readFile(t, dir, "secret.yaml"), "last-rotated.B")
+
+		mustRunDir(t, dir, "update", "--input", "secret.yaml", "--delete-key", "B")
+		assertNotContains(t, readFile(t, dir, "secret.yaml"), "last-rotated.B")
+	})
+
+	t.Run("AddEntryStampsValueList", func(t *testing.T) {
+		dir := t.TempDir()
+		mustRunDir(t, dir, "generate", "--name", "s",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--entry", "alice:pass1", "--output", "secret.yaml")
+		mustRunDir(t, dir, "add-entry", "--input", "secret.yaml",
+			"--entries-key", "USERS", "--entries-val", "PASSES",
+			"--key", "bob", "--value", "pass2", "--stamp")
+		assertContains(t, readFile(t, dir, "secret.yaml"), "last-rotated.PASSES")
+	})
+
+	t.Run("InvalidMaxAge", func(t *testing.T) {
+		dir := t.TempDir()
+		generateBasic(t, dir, "s", "SECRET", "x", "secret.yaml")
+		_, stderr := mustFailDir(t, dir, "rotate", "--input", "secret.yaml", "--key", "SECRET", "--max-age", "soon")
+		assertContains(t, stderr, "invalid max age")
+	})
+}
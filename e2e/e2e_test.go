@@ -8,12 +8,21 @@ package e2e_test
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"filippo.io/age"
 )
 
 // binaryPath holds the path to the compiled binary, set once by TestMain.
@@ -152,3 +161,42 @@ func generateBasic(t *testing.T, dir, name, key, val, out string) {
 		"--output", out,
 	)
 }
+
+// writeSealingKeyPair generates a throwaway RSA keypair and writes a
+// self-signed certificate to certName inside dir. When keyName is non-empty the
+// PKCS#1 private key is written there too.
+func writeSealingKeyPair(t *testing.T, dir, certName, keyName string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create cert: %v", err)
+	}
+	writeFile(t, dir, certName, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	if keyName != "" {
+		writeFile(t, dir, keyName, string(pem.EncodeToMemory(&pem.Block{
+			Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})))
+	}
+}
+
+// writeAgeIdentity writes a fresh age identity file into dir and returns its
+// public recipient string.
+func writeAgeIdentity(t *testing.T, dir, name string) string {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("generate age identity: %v", err)
+	}
+	writeFile(t, dir, name, id.String()+"\n")
+	return id.Recipient().String()
+}
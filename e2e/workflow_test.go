@@ -192,8 +192,7 @@ func TestWorkflow_MultiStepUpdate(t *testing.T) {
 // validate accepts it as a valid kubernetes.io/tls secret.
 func TestWorkflow_GenerateTLSValidate(t *testing.T) {
 	dir := t.TempDir()
-	writeFile(t, dir, "tls.crt", "CERT")
-	writeFile(t, dir, "tls.key", "KEY")
+	writeSealingKeyPair(t, dir, "tls.crt", "tls.key")
 
 	mustRunDir(t, dir, "generate",
 		"--name", "tls-secret",
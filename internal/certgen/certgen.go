@@ -0,0 +1,215 @@
+// Package certgen issues X.509 certificates for kubernetes.io/tls Secrets,
+// either self-signed or signed by a CA certificate and key.
+package certgen
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/keygen"
+	"github.com/pbsladek/k8s-secret-manifest/internal/pemutil"
+)
+
+// DefaultValidity is the lifetime of an issued certificate unless a Request
+// says otherwise.
+const DefaultValidity = 365 * 24 * time.Hour
+
+// Request describes a certificate to issue.
+type Request struct {
+	CommonName   string
+	Organization []string
+	DNSNames     []string
+	IPAddresses  []net.IP
+	Validity     time.Duration
+	// Key is the key to generate for the certificate; it must be an
+	// asymmetric PKCS#8 spec.
+	Key keygen.Spec
+	// IsCA issues a CA certificate that can sign other certificates.
+	IsCA bool
+	// Now is the start of the validity period; the zero value means
+	// time.Now().
+	Now time.Time
+}
+
+// CA is a certificate authority that signs issued certificates.
+type CA struct {
+	Cert    *x509.Certificate
+	CertPEM []byte
+	Key     crypto.Signer
+}
+
+// Issued is a newly issued certificate and its private key, both PEM.
+type Issued struct {
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// AddSAN adds name to the request as an IP address SAN if it parses as one,
+// otherwise as a DNS SAN.
+func (r *Request) AddSAN(name string) {
+	if ip := net.ParseIP(name); ip != nil {
+		r.IPAddresses = append(r.IPAddresses, ip)
+		return
+	}
+	r.DNSNames = append(r.DNSNames, name)
+}
+
+// Issue creates a new key and a certificate for it, signed by ca, or
+// self-signed when ca is nil.
+func Issue(req Request, ca *CA) (*Issued, error) {
+	if req.CommonName == "" && len(req.DNSNames) == 0 && len(req.IPAddresses) == 0 {
+		return nil, errors.New("a common name or at least one SAN is required")
+	}
+	if req.Key.Type == keygen.TypeBytes || req.Key.Format != keygen.FormatPKCS8 {
+		return nil, fmt.Errorf("key type %s cannot be used for a certificate: use rsa, ecdsa, or ed25519", req.Key)
+	}
+	if req.Validity <= 0 {
+		req.Validity = DefaultValidity
+	}
+	now := req.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	key, err := req.Key.NewSigner()
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial number: %w", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: req.CommonName, Organization: req.Organization},
+		DNSNames:     req.DNSNames,
+		IPAddresses:  req.IPAddresses,
+		NotBefore:    now.UTC(),
+		NotAfter:     now.Add(req.Validity).UTC(),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+
+		BasicConstraintsValid: true,
+	}
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	if req.IsCA {
+		tmpl.IsCA = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+
+	parent, signer := tmpl, key
+	if ca != nil {
+		if tmpl.NotAfter.After(ca.Cert.NotAfter) {
+			return nil, fmt.Errorf("certificate would expire after its CA %q (%s)",
+				ca.Cert.Subject.CommonName, ca.Cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+		parent, signer = ca.Cert, ca.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate: %w", err)
+	}
+	m, err := keygen.MarshalPKCS8(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Issued{
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  m.Private,
+	}, nil
+}
+
+// LoadCA parses a CA certificate and its private key from PEM. The
+// certificate must be a CA and the key must belong to it.
+func LoadCA(certPEM, keyPEM []byte) (*CA, error) {
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("certificate %q is not a CA", cert.Subject.CommonName)
+	}
+	key, err := pemutil.ParsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(key.Public()) {
+		return nil, fmt.Errorf("CA key does not match certificate %q", cert.Subject.CommonName)
+	}
+	return &CA{Cert: cert, CertPEM: certPEM, Key: key}, nil
+}
+
+// ReissueRequest returns a request for a certificate with the same subject,
+// SANs, CA flag, validity period, and key type as cert.
+func ReissueRequest(cert *x509.Certificate) (Request, error) {
+	spec, err := keySpec(cert.PublicKey)
+	if err != nil {
+		return Request{}, err
+	}
+	return Request{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		IPAddresses:  cert.IPAddresses,
+		Validity:     cert.NotAfter.Sub(cert.NotBefore),
+		Key:          spec,
+		IsCA:         cert.IsCA,
+	}, nil
+}
+
+// SelfSigned reports whether cert is signed by its own key.
+func SelfSigned(cert *x509.Certificate) bool {
+	return cert.Issuer.String() == cert.Subject.String() &&
+		cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+// ParseCertificate decodes the first CERTIFICATE block in data.
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	certs, err := pemutil.ParseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// keySpec returns the keygen spec that generates keys like pub.
+func keySpec(pub crypto.PublicKey) (keygen.Spec, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return keygen.Spec{Type: keygen.TypeRSA, Format: keygen.FormatPKCS8, Bits: k.N.BitLen()}, nil
+	case *ecdsa.PublicKey:
+		var curve string
+		switch k.Curve {
+		case elliptic.P256():
+			curve = "p256"
+		case elliptic.P384():
+			curve = "p384"
+		case elliptic.P521():
+			curve = "p521"
+		default:
+			return keygen.Spec{}, fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+		}
+		return keygen.Spec{Type: keygen.TypeECDSA, Format: keygen.FormatPKCS8, Curve: curve}, nil
+	case ed25519.PublicKey:
+		return keygen.Spec{Type: keygen.TypeEd25519, Format: keygen.FormatPKCS8}, nil
+	default:
+		return keygen.Spec{}, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
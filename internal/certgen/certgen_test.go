@@ -0,0 +1,114 @@
+package certgen_test
+
+import (
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/certgen"
+	"github.com/pbsladek/k8s-secret-manifest/internal/keygen"
+)
+
+func spec(t *testing.T, s string) keygen.Spec {
+	t.Helper()
+	k, err := keygen.ParseSpec(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func issue(t *testing.T, req certgen.Request, ca *certgen.CA) (*certgen.Issued, *x509.Certificate) {
+	t.Helper()
+	issued, err := certgen.Issue(req, ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := certgen.ParseCertificate(issued.CertPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return issued, cert
+}
+
+func TestIssue_SelfSigned(t *testing.T) {
+	req := certgen.Request{CommonName: "web", Key: spec(t, "ed25519"), Validity: 48 * time.Hour}
+	req.AddSAN("web.internal")
+	req.AddSAN("10.0.0.5")
+	_, cert := issue(t, req, nil)
+
+	if !certgen.SelfSigned(cert) {
+		t.Error("expected a self-signed certificate")
+	}
+	if len(cert.DNSNames) != 1 || cert.DNSNames[0] != "web.internal" {
+		t.Errorf("DNS SANs: %v", cert.DNSNames)
+	}
+	if len(cert.IPAddresses) != 1 || cert.IPAddresses[0].String() != "10.0.0.5" {
+		t.Errorf("IP SANs: %v", cert.IPAddresses)
+	}
+	if got := cert.NotAfter.Sub(cert.NotBefore); got != 48*time.Hour {
+		t.Errorf("validity %v, want 48h", got)
+	}
+}
+
+func TestIssue_CASigned(t *testing.T) {
+	caIssued, _ := issue(t, certgen.Request{CommonName: "Dev CA", Key: spec(t, "rsa:2048"), IsCA: true}, nil)
+	ca, err := certgen.LoadCA(caIssued.CertPEM, caIssued.KeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := certgen.Request{CommonName: "svc", DNSNames: []string{"svc.ns.svc"}, Key: spec(t, "ecdsa:p384"), Validity: time.Hour}
+	_, cert := issue(t, req, ca)
+	if certgen.SelfSigned(cert) {
+		t.Error("expected a CA-signed certificate")
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: pool, DNSName: "svc.ns.svc"}); err != nil {
+		t.Errorf("verify against CA: %v", err)
+	}
+
+	req.Validity = 2 * certgen.DefaultValidity
+	if _, err := certgen.Issue(req, ca); err == nil || !strings.Contains(err.Error(), "expire after its CA") {
+		t.Errorf("expected CA expiry error, got %v", err)
+	}
+}
+
+func TestIssue_Errors(t *testing.T) {
+	if _, err := certgen.Issue(certgen.Request{Key: spec(t, "ed25519")}, nil); err == nil {
+		t.Error("expected error without a common name or SAN")
+	}
+	if _, err := certgen.Issue(certgen.Request{CommonName: "x", Key: spec(t, "ssh-ed25519")}, nil); err == nil {
+		t.Error("expected error for an OpenSSH key type")
+	}
+}
+
+func TestLoadCA_Errors(t *testing.T) {
+	leaf, _ := issue(t, certgen.Request{CommonName: "leaf", Key: spec(t, "ed25519")}, nil)
+	if _, err := certgen.LoadCA(leaf.CertPEM, leaf.KeyPEM); err == nil || !strings.Contains(err.Error(), "not a CA") {
+		t.Errorf("expected not-a-CA error, got %v", err)
+	}
+
+	ca, _ := issue(t, certgen.Request{CommonName: "ca", Key: spec(t, "ed25519"), IsCA: true}, nil)
+	if _, err := certgen.LoadCA(ca.CertPEM, leaf.KeyPEM); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected key mismatch error, got %v", err)
+	}
+}
+
+func TestReissueRequest(t *testing.T) {
+	req := certgen.Request{CommonName: "web", Organization: []string{"Acme"}, Key: spec(t, "ecdsa:p384"), Validity: 72 * time.Hour}
+	req.AddSAN("a.example")
+	req.AddSAN("::1")
+	_, cert := issue(t, req, nil)
+
+	again, err := certgen.ReissueRequest(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Key.String() != "ecdsa:p384" || again.Validity != 72*time.Hour || again.CommonName != "web" ||
+		again.Organization[0] != "Acme" || again.DNSNames[0] != "a.example" || !again.IPAddresses[0].Equal(cert.IPAddresses[0]) {
+		t.Errorf("reissue request does not match original: %+v", again)
+	}
+}
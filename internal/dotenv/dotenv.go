@@ -0,0 +1,326 @@
+// Package dotenv parses .env files.
+//
+// The dialect is the common one shared by shells, docker compose, and most
+// dotenv libraries:
+//
+//	# full-line comment
+//	export KEY=value           # "export " is optional
+//	PLAIN=unquoted value       # inline comments need whitespace before '#'
+//	SINGLE='literal $ \ text'  # no escapes, no interpolation
+//	DOUBLE="tab\tnewline\n"    # \n \r \t \" \\ \$ escapes
+//	PEM="-----BEGIN KEY-----
+//	...
+//	-----END KEY-----"         # quoted values may span lines
+//	URL=https://${HOST}/api    # ${VAR} in unquoted and double-quoted values
+//	BLOB=!!binary AAEC/w==     # arbitrary bytes, standard base64
+//
+// ${VAR} refers to a key set earlier in the file, or else to the lookup
+// function given to Parse; an unset variable is an error. A bare '$' is
+// literal. Keys may appear only once. Errors carry the line and column of
+// the offending text but never quote it, since .env files hold secrets.
+package dotenv
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// BinaryMarker introduces a base64-encoded value: KEY=!!binary AAEC/w==.
+// Only unquoted values are decoded; "!!binary ..." in quotes is literal.
+const BinaryMarker = "!!binary"
+
+// SyntaxError is a malformed .env file. Line and Column are 1-based; the
+// column counts characters, not bytes.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Parse parses a .env file and returns its keys and values. lookup resolves
+// ${VAR} references to variables not set in the file; nil means none.
+func Parse(data []byte, lookup func(string) (string, bool)) (map[string]string, error) {
+	src := strings.TrimPrefix(string(data), "\ufeff")
+	p := &parser{
+		src:    strings.ReplaceAll(src, "\r\n", "\n"),
+		line:   1,
+		lookup: lookup,
+		vars:   make(map[string]string),
+		lines:  make(map[string]int),
+	}
+	for {
+		p.skipBlanks()
+		if p.eof() {
+			return p.vars, nil
+		}
+		switch p.peek() {
+		case '\n':
+			p.next()
+		case '#':
+			p.skipLine()
+		default:
+			if err := p.assignment(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+type parser struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int
+	lookup    func(string) (string, bool)
+	vars      map[string]string
+	lines     map[string]int // line each key was set on
+}
+
+// position is a point in the source, for error messages.
+type position struct{ line, col int }
+
+func (p *parser) here() position {
+	return position{p.line, utf8.RuneCountInString(p.src[p.lineStart:p.pos]) + 1}
+}
+
+func (p *parser) errorf(at position, format string, args ...any) error {
+	return &SyntaxError{Line: at.line, Column: at.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool  { return p.pos >= len(p.src) }
+func (p *parser) peek() byte { return p.src[p.pos] }
+
+// next consumes one byte, keeping track of line starts.
+func (p *parser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+		p.lineStart = p.pos
+	}
+	return c
+}
+
+func (p *parser) skipBlanks() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipLine skips to the end of the line, leaving the newline unconsumed.
+func (p *parser) skipLine() {
+	if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+		p.pos += i
+	} else {
+		p.pos = len(p.src)
+	}
+}
+
+// assignment parses one KEY=value line.
+func (p *parser) assignment() error {
+	if rest, ok := strings.CutPrefix(p.src[p.pos:], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+		p.pos += len("export")
+		p.skipBlanks()
+	}
+
+	at := p.here()
+	start := p.pos
+	for !p.eof() && p.peek() != '=' && p.peek() != '\n' {
+		p.pos++
+	}
+	if p.eof() || p.peek() != '=' {
+		return p.errorf(at, "expected KEY=value")
+	}
+	key := strings.TrimRight(p.src[start:p.pos], " \t")
+	if key == "" {
+		return p.errorf(at, "empty key")
+	}
+	if first, ok := p.lines[key]; ok {
+		return p.errorf(at, "duplicate key %q (first set on line %d)", key, first)
+	}
+	p.pos++ // '='
+	p.skipBlanks()
+
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	p.vars[key] = value
+	p.lines[key] = at.line
+	return nil
+}
+
+func (p *parser) value() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+	if strings.HasPrefix(p.src[p.pos:], BinaryMarker+" ") || strings.HasPrefix(p.src[p.pos:], BinaryMarker+"\t") {
+		return p.binary()
+	}
+	switch p.peek() {
+	case '\'':
+		return p.singleQuoted()
+	case '"':
+		return p.doubleQuoted()
+	default:
+		return p.unquoted()
+	}
+}
+
+// unquoted reads to the end of the line or an inline comment, trimming
+// trailing whitespace.
+func (p *parser) unquoted() (string, error) {
+	var sb strings.Builder
+	for !p.eof() && p.peek() != '\n' {
+		c := p.peek()
+		if c == '#' && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			p.skipLine()
+			break
+		}
+		if c == '$' && strings.HasPrefix(p.src[p.pos:], "${") {
+			if err := p.interpolate(&sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	return strings.TrimRight(sb.String(), " \t"), nil
+}
+
+// binary decodes an unquoted "!!binary <base64>" value.
+func (p *parser) binary() (string, error) {
+	p.pos += len(BinaryMarker)
+	p.skipBlanks()
+	at := p.here()
+	start := p.pos
+	p.skipLine()
+	encoded := p.src[start:p.pos]
+	if i := strings.IndexAny(encoded, " \t"); i >= 0 {
+		if rest := strings.TrimLeft(encoded[i:], " \t"); rest != "" && rest[0] != '#' {
+			return "", p.errorf(at, "invalid base64 after %s", BinaryMarker)
+		}
+		encoded = encoded[:i]
+	}
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", p.errorf(at, "invalid base64 after %s", BinaryMarker)
+	}
+	return string(b), nil
+}
+
+func (p *parser) singleQuoted() (string, error) {
+	at := p.here()
+	p.next() // opening quote
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		p.next()
+	}
+	if p.eof() {
+		return "", p.errorf(at, "unterminated single-quoted value")
+	}
+	value := p.src[start:p.pos]
+	p.next() // closing quote
+	return value, p.afterQuote()
+}
+
+func (p *parser) doubleQuoted() (string, error) {
+	at := p.here()
+	p.next() // opening quote
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(at, "unterminated double-quoted value")
+		}
+		switch c := p.peek(); {
+		case c == '"':
+			p.next()
+			return sb.String(), p.afterQuote()
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.next()
+			switch e := p.next(); e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '$':
+				sb.WriteByte(e)
+			default:
+				// Unknown escapes are kept as written, as in the shell.
+				sb.WriteByte('\\')
+				sb.WriteByte(e)
+			}
+		case c == '$' && strings.HasPrefix(p.src[p.pos:], "${"):
+			if err := p.interpolate(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(p.next())
+		}
+	}
+}
+
+// afterQuote allows only whitespace and a comment after a closing quote.
+func (p *parser) afterQuote() error {
+	p.skipBlanks()
+	if p.eof() || p.peek() == '\n' {
+		return nil
+	}
+	if p.peek() == '#' {
+		p.skipLine()
+		return nil
+	}
+	return p.errorf(p.here(), "unexpected text after closing quote")
+}
+
+// interpolate expands the ${VAR} reference at the current position.
+func (p *parser) interpolate(sb *strings.Builder) error {
+	at := p.here()
+	p.pos += len("${")
+	end := strings.IndexAny(p.src[p.pos:], "}\n")
+	if end < 0 || p.src[p.pos+end] != '}' {
+		return p.errorf(at, "unterminated ${ reference")
+	}
+	name := p.src[p.pos : p.pos+end]
+	if !validName(name) {
+		return p.errorf(at, "invalid variable name in ${ reference")
+	}
+	p.pos += end + 1
+
+	if v, ok := p.vars[name]; ok {
+		sb.WriteString(v)
+		return nil
+	}
+	if p.lookup != nil {
+		if v, ok := p.lookup(name); ok {
+			sb.WriteString(v)
+			return nil
+		}
+	}
+	return p.errorf(at, "variable %q is not set", name)
+}
+
+// validName reports whether name can be referenced as ${name}: the
+// characters allowed in Secret data keys.
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
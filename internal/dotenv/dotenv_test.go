@@ -0,0 +1,138 @@
+package dotenv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse_Values(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string // value of KEY
+	}{
+		{"unquoted", "KEY=value", "value"},
+		{"empty", "KEY=", ""},
+		{"spaces around equals", "KEY = value  ", "value"},
+		{"export prefix", "export KEY=value", "value"},
+		{"inner spaces kept", "KEY=two words", "two words"},
+		{"inline comment", "KEY=value # comment", "value"},
+		{"hash without space", "KEY=pa#ss", "pa#ss"},
+		{"hash at value start", "KEY=#value", "#value"},
+		{"equals in value", "KEY=a=b", "a=b"},
+		{"bare dollar", "KEY=pa$$word", "pa$$word"},
+		{"backslash unquoted", `KEY=C:\path\n`, `C:\path\n`},
+		{"single quoted", `KEY='a $b \n # c'`, `a $b \n # c`},
+		{"single quoted comment", "KEY='value' # comment", "value"},
+		{"double quoted", `KEY="a # b"`, "a # b"},
+		{"escapes", `KEY="tab\there\nnew \"q\" \\ \$HOME"`, "tab\there\nnew \"q\" \\ $HOME"},
+		{"unknown escape kept", `KEY="a\qb"`, `a\qb`},
+		{"multi-line double", "KEY=\"line1\nline2\n\"", "line1\nline2\n"},
+		{"multi-line single", "KEY='line1\nline2'", "line1\nline2"},
+		{"crlf", "KEY=value\r\nOTHER=x\r\n", "value"},
+		{"crlf in quotes", "KEY=\"a\r\nb\"\r\n", "a\nb"},
+		{"byte order mark", "\ufeffKEY=value", "value"},
+		{"binary", "KEY=!!binary AAH/", "\x00\x01\xff"},
+		{"binary with comment", "KEY=!!binary  AAH/  # raw bytes", "\x00\x01\xff"},
+		{"binary empty", "KEY=!!binary ", ""},
+		{"quoted marker is literal", `KEY="!!binary AAH/"`, "!!binary AAH/"},
+		{"marker without space is literal", "KEY=!!binary", "!!binary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.input), nil)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got["KEY"] != tt.want {
+				t.Errorf("KEY = %q, want %q", got["KEY"], tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_CommentsAndBlankLines(t *testing.T) {
+	got, err := Parse([]byte("# comment\n\n   \n  # indented\nA=1\n\nB=2"), nil)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(got) != 2 || got["A"] != "1" || got["B"] != "2" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestParse_LargeValue(t *testing.T) {
+	big := strings.Repeat("x", 200*1024)
+	got, err := Parse([]byte("KEY="+big+"\nNEXT=1\n"), nil)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(got["KEY"]) != len(big) || got["NEXT"] != "1" {
+		t.Errorf("large value truncated: %d bytes", len(got["KEY"]))
+	}
+}
+
+func TestParse_Interpolation(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me", "HOST": "from-env"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	input := "HOST=db\nURL=postgres://${HOST}/app\nQUOTED=\"${HOST}:${HOME}\"\nLITERAL='${HOST}'\nESCAPED=\"\\${HOST}\"\n"
+	got, err := Parse([]byte(input), lookup)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for key, want := range map[string]string{
+		"URL":     "postgres://db/app",
+		"QUOTED":  "db:/home/me",
+		"LITERAL": "${HOST}",
+		"ESCAPED": "${HOST}",
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %q, want %q", key, got[key], want)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		line, col int
+		msg       string
+	}{
+		{"missing equals", "A=1\nNOEQUALS\n", 2, 1, "expected KEY=value"},
+		{"empty key", "=value", 1, 1, "empty key"},
+		{"duplicate", "A=1\n\n  A=2", 3, 3, `duplicate key "A" (first set on line 1)`},
+		{"unterminated double", "A=1\nB=\"abc\n\n", 2, 3, "unterminated double-quoted value"},
+		{"unterminated single", "B='abc", 1, 3, "unterminated single-quoted value"},
+		{"text after quote", `B="abc"def`, 1, 8, "unexpected text after closing quote"},
+		{"unset variable", "B=x${MISSING}", 1, 4, `variable "MISSING" is not set`},
+		{"unterminated reference", "B=${HOST\nC=1", 1, 3, "unterminated ${ reference"},
+		{"invalid reference", "B=${A B}", 1, 3, "invalid variable name"},
+		{"invalid base64", "B=!!binary not-base64", 1, 12, "invalid base64 after !!binary"},
+		{"base64 with trailing text", "B=!!binary AAH/ extra", 1, 12, "invalid base64"},
+		{"column counts characters", "B=\"ü\"x", 1, 6, "unexpected text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input), nil)
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Parse = %v, want a SyntaxError", err)
+			}
+			if se.Line != tt.line || se.Column != tt.col || !strings.Contains(se.Msg, tt.msg) {
+				t.Errorf("got %v, want line %d, column %d: %s", err, tt.line, tt.col, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParse_ErrorsDoNotLeakValues(t *testing.T) {
+	_, err := Parse([]byte(`KEY="s3cr3t"trailing`), nil)
+	if err == nil || strings.Contains(err.Error(), "s3cr3t") || strings.Contains(err.Error(), "trailing") {
+		t.Errorf("error = %v, want one that does not quote the value", err)
+	}
+}
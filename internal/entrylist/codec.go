@@ -0,0 +1,236 @@
+package entrylist
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrNeedsQuoting is wrapped by the errors for items that a Codec without
+// quoting cannot store.
+var ErrNeedsQuoting = errors.New("cannot be stored without quoting")
+
+// Codec reads and writes parallel lists with a given separator and, when Quote
+// is set, quotes items that would otherwise not survive a round trip.
+//
+// With quoting, an item that is empty, contains Sep, has leading or trailing
+// whitespace, or starts with Quote is written between two Quote characters,
+// with every Quote inside doubled (as in CSV). Unquoted items are read as
+// before, so existing lists parse the same as long as no item starts with
+// the quote character.
+type Codec struct {
+	Sep string
+	// Quote is the quote character; empty disables quoting, and items that
+	// would need it are rejected.
+	Quote string
+}
+
+// item is one list item as read: its text and whether it was quoted.
+type item struct {
+	text   string
+	quoted bool
+}
+
+// Validate reports whether the separator and quote character can be used
+// together.
+func (c Codec) Validate() error {
+	if c.Sep == "" {
+		return errors.New("separator must not be empty")
+	}
+	if c.Quote == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(c.Quote) != 1 {
+		return fmt.Errorf("quote %q must be a single character", c.Quote)
+	}
+	q, _ := utf8.DecodeRuneInString(c.Quote)
+	if unicode.IsSpace(q) || strings.Contains(c.Sep, c.Quote) {
+		return fmt.Errorf("quote %q must not be whitespace or part of the separator", c.Quote)
+	}
+	if strings.TrimSpace(c.Sep) == "" {
+		return fmt.Errorf("a whitespace separator cannot be combined with quoting")
+	}
+	return nil
+}
+
+// unsafe returns why s needs quoting, or "" when it can be stored as is.
+func (c Codec) unsafe(s string) string {
+	if c.Quote != "" {
+		return ""
+	}
+	switch {
+	case s == "":
+		return "is empty"
+	case strings.Contains(s, c.Sep):
+		return fmt.Sprintf("contains the separator %q", c.Sep)
+	case strings.TrimSpace(s) != s:
+		return "has leading or trailing whitespace"
+	}
+	return ""
+}
+
+// encode quotes s if quoting is enabled and s needs it.
+func (c Codec) encode(s string) string {
+	if c.Quote == "" {
+		return s
+	}
+	if s != "" && !strings.Contains(s, c.Sep) && strings.TrimSpace(s) == s && !strings.HasPrefix(s, c.Quote) {
+		return s
+	}
+	return c.Quote + strings.ReplaceAll(s, c.Quote, c.Quote+c.Quote) + c.Quote
+}
+
+// split splits a list into items. Unquoted items are trimmed; a blank list
+// has no items.
+func (c Codec) split(list string) ([]item, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	if c.Quote == "" {
+		parts := strings.Split(list, c.Sep)
+		items := make([]item, len(parts))
+		for i, p := range parts {
+			items[i] = item{text: strings.TrimSpace(p)}
+		}
+		return items, nil
+	}
+
+	var items []item
+	rest := list
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if !strings.HasPrefix(rest, c.Quote) {
+			text, after, found := strings.Cut(rest, c.Sep)
+			items = append(items, item{text: strings.TrimSpace(text)})
+			if !found {
+				return items, nil
+			}
+			rest = after
+			continue
+		}
+
+		var b strings.Builder
+		body := rest[len(c.Quote):]
+		for {
+			i := strings.Index(body, c.Quote)
+			if i < 0 {
+				return nil, fmt.Errorf("item %d: missing closing quote", len(items))
+			}
+			b.WriteString(body[:i])
+			body = body[i+len(c.Quote):]
+			if !strings.HasPrefix(body, c.Quote) {
+				break
+			}
+			b.WriteString(c.Quote)
+			body = body[len(c.Quote):]
+		}
+		items = append(items, item{text: b.String(), quoted: true})
+
+		body = strings.TrimLeftFunc(body, unicode.IsSpace)
+		if body == "" {
+			return items, nil
+		}
+		after, ok := strings.CutPrefix(body, c.Sep)
+		if !ok {
+			return nil, fmt.Errorf("item %d: unexpected text after closing quote", len(items)-1)
+		}
+		rest = after
+	}
+}
+
+// dropEmpty returns the text of items without the unquoted empty ones.
+func dropEmpty(items []item) []string {
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		if it.text != "" || it.quoted {
+			out = append(out, it.text)
+		}
+	}
+	return out
+}
+
+// Problem kinds reported by Inspect.
+const (
+	ProblemLengthMismatch = "length-mismatch"
+	ProblemDuplicateKey   = "duplicate-key"
+	ProblemEmptyKey       = "empty-key"
+	ProblemEmptyValue     = "empty-value"
+)
+
+// Row is one index position of two paired lists as stored, before ParseTable
+// drops empty items. HasKey or HasValue is false when that list is too short
+// to reach Index.
+type Row struct {
+	Index    int
+	Key      string
+	Value    string
+	HasKey   bool
+	HasValue bool
+}
+
+// Problem is an inconsistency in two paired lists found by Inspect.
+type Problem struct {
+	Kind    string
+	Index   int
+	Key     string
+	Message string
+}
+
+// Inspect splits keysVal and valuesVal without dropping empty items, so
+// every row lines up with what is stored, and reports what ParseTable would
+// reject or silently change: lists of different lengths, duplicate keys,
+// empty keys, and unquoted empty values. It returns an error only for a
+// malformed quoted item.
+func (c Codec) Inspect(keysVal, valuesVal string) ([]Row, []Problem, error) {
+	keys, err := c.split(keysVal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key list: %w", err)
+	}
+	values, err := c.split(valuesVal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("value list: %w", err)
+	}
+
+	var problems []Problem
+	if len(keys) != len(values) {
+		n := min(len(keys), len(values))
+		problems = append(problems, Problem{
+			Kind:    ProblemLengthMismatch,
+			Index:   n,
+			Message: fmt.Sprintf("%d key(s) but %d value(s): entries from index %d on are unpaired", len(keys), len(values), n),
+		})
+	}
+
+	rows := make([]Row, max(len(keys), len(values)))
+	first := make(map[string]int, len(keys))
+	for i := range rows {
+		r := Row{Index: i}
+		if i < len(keys) {
+			r.Key, r.HasKey = keys[i].text, true
+		}
+		if i < len(values) {
+			r.Value, r.HasValue = values[i].text, true
+		}
+		rows[i] = r
+
+		switch {
+		case r.HasKey && r.Key == "":
+			problems = append(problems, Problem{Kind: ProblemEmptyKey, Index: i,
+				Message: fmt.Sprintf("empty key at index %d", i)})
+		case r.HasKey:
+			if j, dup := first[r.Key]; dup {
+				problems = append(problems, Problem{Kind: ProblemDuplicateKey, Index: i, Key: r.Key,
+					Message: fmt.Sprintf("key %q at index %d duplicates index %d", r.Key, i, j)})
+			} else {
+				first[r.Key] = i
+			}
+		}
+		if r.HasValue && r.Value == "" && !values[i].quoted {
+			problems = append(problems, Problem{Kind: ProblemEmptyValue, Index: i, Key: r.Key,
+				Message: fmt.Sprintf("empty value at index %d is dropped when the lists are parsed", i)})
+		}
+	}
+	return rows, problems, nil
+}
@@ -0,0 +1,191 @@
+package entrylist
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var pair = []string{"USERS", "PASSWORDS"}
+
+// ---- CheckRow ----
+
+func TestCheckRow_RejectsUnsafeItemsWithoutQuoting(t *testing.T) {
+	c := Codec{Sep: ";"}
+	tbl, err := NewTable(pair...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		key, value string
+		want       string
+	}{
+		{"separator in value", "bob", "p;ss", "contains the separator"},
+		{"separator in key", "b;ob", "pass", "contains the separator"},
+		{"leading whitespace", "bob", " pass", "whitespace"},
+		{"trailing whitespace", "bob", "pass\t", "whitespace"},
+		{"empty value", "bob", "", "is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.CheckRow(tbl, []string{tt.key, tt.value})
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !errors.Is(err, ErrNeedsQuoting) {
+				t.Fatalf("CheckRow(%q, %q) = %v, want error containing %q", tt.key, tt.value, err, tt.want)
+			}
+			if tt.value != "" && strings.Contains(err.Error(), tt.value) {
+				t.Errorf("error leaks the value: %v", err)
+			}
+		})
+	}
+	if err := c.CheckRow(tbl, []string{"", "pass"}); err == nil {
+		t.Error("expected error for empty key")
+	}
+	if err := c.CheckRow(tbl, []string{"bob", "p ss"}); err != nil {
+		t.Errorf("inner whitespace should be allowed: %v", err)
+	}
+}
+
+// ---- quoting ----
+
+func TestQuoting_RoundTrip(t *testing.T) {
+	c := Codec{Sep: ";", Quote: `"`}
+	tbl := Table{Columns: pair, Rows: [][]string{
+		{"alice", "plain"},
+		{"bob", "p;ss"},
+		{"carol", ""},
+		{"dave", "  padded  "},
+		{"eve", `"starts with quote`},
+		{"frank", `mid"quote`},
+		{"gina", `";"`},
+		{"h;i", "x"},
+	}}
+	lists, err := c.SerializeTable(tbl)
+	if err != nil {
+		t.Fatalf("SerializeTable: %v", err)
+	}
+	got, err := c.ParseTable(pair, lists)
+	if err != nil {
+		t.Fatalf("ParseTable(%q): %v", lists, err)
+	}
+	if len(got.Rows) != len(tbl.Rows) {
+		t.Fatalf("got %d rows, want %d: %q", len(got.Rows), len(tbl.Rows), got.Rows)
+	}
+	for i := range tbl.Rows {
+		if !slices.Equal(got.Rows[i], tbl.Rows[i]) {
+			t.Errorf("Rows[%d] = %q, want %q", i, got.Rows[i], tbl.Rows[i])
+		}
+	}
+}
+
+func TestQuoting_OnlyWhenNeeded(t *testing.T) {
+	c := Codec{Sep: ";", Quote: `"`}
+	lists, err := c.SerializeTable(Table{Columns: pair, Rows: [][]string{{"a", "pass1"}, {"b", "p;2"}, {"c", ""}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `pass1;"p;2";""`; lists[1] != want {
+		t.Errorf("values = %q, want %q", lists[1], want)
+	}
+}
+
+func TestQuoting_ReadsLegacyLists(t *testing.T) {
+	c := Codec{Sep: ";", Quote: `"`}
+	tbl, err := c.ParseTable(pair, []string{" alice ; bob ", "pass1; pass2;"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(tbl.Rows[0], []string{"alice", "pass1"}) || !slices.Equal(tbl.Rows[1], []string{"bob", "pass2"}) {
+		t.Errorf("unexpected rows: %q", tbl.Rows)
+	}
+}
+
+func TestQuoting_Malformed(t *testing.T) {
+	c := Codec{Sep: ";", Quote: `"`}
+	for _, vals := range []string{`"unterminated;b`, `"a"x;b`} {
+		if _, err := c.ParseTable(pair, []string{"a;b", vals}); err == nil {
+			t.Errorf("ParseTable(%q): expected error", vals)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, c := range []Codec{
+		{Sep: ""},
+		{Sep: ";", Quote: `""`},
+		{Sep: ";", Quote: " "},
+		{Sep: ";", Quote: ";"},
+		{Sep: " ", Quote: `"`},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v): expected error", c)
+		}
+	}
+	for _, c := range []Codec{{Sep: ";"}, {Sep: ",", Quote: "'"}, {Sep: " "}} {
+		if err := c.Validate(); err != nil {
+			t.Errorf("Validate(%+v): %v", c, err)
+		}
+	}
+}
+
+// ---- Inspect ----
+
+func TestInspect_Clean(t *testing.T) {
+	rows, problems, err := Codec{Sep: ";"}.Inspect("alice;bob", "pass1;pass2")
+	if err != nil || len(problems) != 0 {
+		t.Errorf("unexpected problems: %+v, %v", problems, err)
+	}
+	if len(rows) != 2 || rows[1] != (Row{Index: 1, Key: "bob", Value: "pass2", HasKey: true, HasValue: true}) {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+}
+
+func TestInspect_Problems(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys, vals string
+		want       []string
+	}{
+		{"length mismatch", "alice;bob;carol", "pass1;pass2", []string{ProblemLengthMismatch}},
+		{"duplicate key", "alice;bob;alice", "pass1;pass2;pass3", []string{ProblemDuplicateKey}},
+		{"empty value", "alice;bob", "pass1;", []string{ProblemEmptyValue}},
+		{"empty key", ";bob", "pass1;pass2", []string{ProblemEmptyKey}},
+		{"empty value in the middle", "alice;bob;carol", "pass1; ;pass3", []string{ProblemEmptyValue}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems, err := Codec{Sep: ";"}.Inspect(tt.keys, tt.vals)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var kinds []string
+			for _, p := range problems {
+				kinds = append(kinds, p.Kind)
+			}
+			if strings.Join(kinds, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("problems = %v, want %v", kinds, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspect_RowsKeepEmptyItems(t *testing.T) {
+	// ParseTable drops the empty value and misreports the pairing; Inspect keeps
+	// every row at its stored index.
+	rows, _, _ := Codec{Sep: ";"}.Inspect("alice;bob;carol", "pass1;;pass3")
+	if len(rows) != 3 || rows[2].Key != "carol" || rows[2].Value != "pass3" {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+	rows, _, _ = Codec{Sep: ";"}.Inspect("alice", "pass1;pass2")
+	if len(rows) != 2 || rows[1].HasKey || !rows[1].HasValue {
+		t.Errorf("unexpected rows for unpaired value: %+v", rows)
+	}
+}
+
+func TestInspect_QuotedEmptyValueIsFine(t *testing.T) {
+	_, problems, err := Codec{Sep: ";", Quote: `"`}.Inspect("alice;bob", `pass1;""`)
+	if err != nil || len(problems) != 0 {
+		t.Errorf("unexpected problems: %+v, %v", problems, err)
+	}
+}
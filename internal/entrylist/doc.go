@@ -0,0 +1,21 @@
+// Package entrylist manages two parallel delimiter-separated lists stored in
+// two separate K8s Secret data keys, where entries match by index position.
+//
+// Example — two data keys whose values are index-paired (separator ";"):
+//
+//	BACKEND_USERS:     alice;bob;carol
+//	BACKEND_PASSWORDS: pass1;pass2;pass3
+//
+// Position determines pairing: index 0 of keys ↔ index 0 of values.
+// A Table holds any number of such parallel lists, the first one naming
+// the entries.
+// The separator is configurable (default ";") and values are stored as plain
+// text (base64 encoding is handled by the manifest layer).
+//
+// Items are trimmed and empty items are dropped when a list is parsed, so an
+// item that contains the separator, has surrounding whitespace, or is empty
+// cannot be stored as is. A Codec rejects such items, or with quoting
+// enabled stores them quoted:
+//
+//	BACKEND_PASSWORDS: pass1;"p;ss2";""
+package entrylist
@@ -0,0 +1,239 @@
+package entrylist
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Table is N parallel lists stored in N data keys and matched by index
+// position, e.g. users, passwords, and databases:
+//
+//	DB_USERS:     alice;bob
+//	DB_PASSWORDS: pass1;pass2
+//	DB_NAMES:     app;reporting
+//
+// Columns holds the data key of each list. Column 0 is the primary column:
+// its items identify the rows and must be non-empty and unique. Rows[i]
+// holds item i of every column, in column order.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// NewTable returns an empty table with the given columns, the first being
+// the primary column. At least two distinct columns are required.
+func NewTable(columns ...string) (Table, error) {
+	if len(columns) < 2 {
+		return Table{}, fmt.Errorf("a table needs an identifier column and at least one value column")
+	}
+	for i, col := range columns {
+		if col == "" {
+			return Table{}, fmt.Errorf("column %d has no name", i)
+		}
+		if slices.Index(columns, col) != i {
+			return Table{}, fmt.Errorf("column %q given more than once", col)
+		}
+	}
+	return Table{Columns: slices.Clone(columns), Rows: [][]string{}}, nil
+}
+
+// ParseTable decodes one list per column into a table. Every column must
+// have the same number of items, and primary items must be non-empty and
+// unique. Unquoted items are trimmed and unquoted empty items are dropped.
+func (c Codec) ParseTable(columns, lists []string) (Table, error) {
+	t, err := NewTable(columns...)
+	if err != nil {
+		return Table{}, err
+	}
+	if len(lists) != len(columns) {
+		return Table{}, fmt.Errorf("%d list(s) for %d column(s)", len(lists), len(columns))
+	}
+
+	cols := make([][]string, len(columns))
+	for i, list := range lists {
+		items, err := c.split(list)
+		if err != nil {
+			return Table{}, fmt.Errorf("column %s: %w", columns[i], err)
+		}
+		cols[i] = dropEmpty(items)
+		if len(cols[i]) != len(cols[0]) {
+			return Table{}, fmt.Errorf("entry list mismatch: column %s has %d item(s) but %s has %d",
+				columns[i], len(cols[i]), columns[0], len(cols[0]))
+		}
+	}
+
+	for r := range cols[0] {
+		row := make([]string, len(columns))
+		for i := range columns {
+			row[i] = cols[i][r]
+		}
+		if row[0] == "" {
+			return Table{}, fmt.Errorf("empty key at index %d", r)
+		}
+		if j := t.Index(row[0]); j >= 0 {
+			return Table{}, fmt.Errorf("duplicate key %q at index %d and %d", row[0], j, r)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// SerializeTable encodes the table into one list per column, in column
+// order. It returns an error for a row that cannot be stored (see CheckRow).
+func (c Codec) SerializeTable(t Table) ([]string, error) {
+	cols := make([][]string, len(t.Columns))
+	for _, row := range t.Rows {
+		if err := c.CheckRow(t, row); err != nil {
+			return nil, err
+		}
+		for i, item := range row {
+			cols[i] = append(cols[i], c.encode(item))
+		}
+	}
+	lists := make([]string, len(t.Columns))
+	for i := range cols {
+		lists[i] = strings.Join(cols[i], c.Sep)
+	}
+	return lists, nil
+}
+
+// CheckRow reports whether row fits t and can be stored: one item per
+// column, a non-empty identifier, and without quoting no item that is empty,
+// contains the separator, or has surrounding whitespace. Only the identifier
+// appears in the error.
+func (c Codec) CheckRow(t Table, row []string) error {
+	if len(row) != len(t.Columns) {
+		return fmt.Errorf("entry has %d item(s) but the table has %d column(s)", len(row), len(t.Columns))
+	}
+	if row[0] == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+	if reason := c.unsafe(row[0]); reason != "" {
+		return fmt.Errorf("key %q %s and %w", row[0], reason, ErrNeedsQuoting)
+	}
+	for i, item := range row[1:] {
+		if reason := c.unsafe(item); reason != "" {
+			return fmt.Errorf("%s of entry %q %s and %w", t.Columns[i+1], row[0], reason, ErrNeedsQuoting)
+		}
+	}
+	return nil
+}
+
+// Index returns the position of the row with the given identifier, or -1.
+func (t Table) Index(key string) int {
+	return slices.IndexFunc(t.Rows, func(row []string) bool { return row[0] == key })
+}
+
+// Keys returns the identifiers in row order.
+func (t Table) Keys() []string {
+	keys := make([]string, len(t.Rows))
+	for i, row := range t.Rows {
+		keys[i] = row[0]
+	}
+	return keys
+}
+
+// Add appends a row. Returns an error if the row does not have one item per
+// column or its identifier already exists.
+func (t Table) Add(row []string) (Table, error) {
+	return t.Insert(len(t.Rows), row)
+}
+
+// Insert inserts a row at index idx. Index 0 prepends; index len(t.Rows)
+// appends.
+func (t Table) Insert(idx int, row []string) (Table, error) {
+	if len(row) != len(t.Columns) {
+		return Table{}, fmt.Errorf("entry has %d item(s) but the table has %d column(s)", len(row), len(t.Columns))
+	}
+	if row[0] == "" {
+		return Table{}, fmt.Errorf("key must not be empty")
+	}
+	if idx < 0 || idx > len(t.Rows) {
+		return Table{}, fmt.Errorf("index %d out of range [0, %d]", idx, len(t.Rows))
+	}
+	if t.Index(row[0]) >= 0 {
+		return Table{}, fmt.Errorf("entry %q already exists", row[0])
+	}
+	t.Rows = slices.Insert(slices.Clone(t.Rows), idx, slices.Clone(row))
+	return t, nil
+}
+
+// Remove removes the row with the given identifier.
+func (t Table) Remove(key string) (Table, error) {
+	i := t.Index(key)
+	if i < 0 {
+		return Table{}, fmt.Errorf("entry with key %q not found", key)
+	}
+	t.Rows = slices.Delete(slices.Clone(t.Rows), i, i+1)
+	return t, nil
+}
+
+// RemoveWhere removes the first row whose item in column col equals value.
+func (t Table) RemoveWhere(col int, value string) (Table, error) {
+	if col < 0 || col >= len(t.Columns) {
+		return Table{}, fmt.Errorf("column %d out of range", col)
+	}
+	i := slices.IndexFunc(t.Rows, func(row []string) bool { return row[col] == value })
+	if i < 0 {
+		return Table{}, fmt.Errorf("entry with %s %q not found", t.Columns[col], value)
+	}
+	t.Rows = slices.Delete(slices.Clone(t.Rows), i, i+1)
+	return t, nil
+}
+
+// Update replaces the value items of the row with the given identifier,
+// keeping its position. values holds one item per value column.
+func (t Table) Update(key string, values []string) (Table, error) {
+	if len(values) != len(t.Columns)-1 {
+		return Table{}, fmt.Errorf("%d value(s) for %d value column(s)", len(values), len(t.Columns)-1)
+	}
+	i := t.Index(key)
+	if i < 0 {
+		return Table{}, fmt.Errorf("entry with key %q not found", key)
+	}
+	t.Rows = slices.Clone(t.Rows)
+	t.Rows[i] = append([]string{key}, values...)
+	return t, nil
+}
+
+// Rename changes the identifier of the row oldKey to newKey, keeping its
+// values and position.
+func (t Table) Rename(oldKey, newKey string) (Table, error) {
+	if newKey == "" {
+		return Table{}, fmt.Errorf("key must not be empty")
+	}
+	i := t.Index(oldKey)
+	if i < 0 {
+		return Table{}, fmt.Errorf("entry with key %q not found", oldKey)
+	}
+	if newKey != oldKey && t.Index(newKey) >= 0 {
+		return Table{}, fmt.Errorf("entry %q already exists", newKey)
+	}
+	t.Rows = slices.Clone(t.Rows)
+	t.Rows[i] = append([]string{newKey}, t.Rows[i][1:]...)
+	return t, nil
+}
+
+// Move moves the row with the given identifier to index idx, keeping the
+// items of every column together.
+func (t Table) Move(key string, idx int) (Table, error) {
+	i := t.Index(key)
+	if i < 0 {
+		return Table{}, fmt.Errorf("entry with key %q not found", key)
+	}
+	if idx < 0 || idx >= len(t.Rows) {
+		return Table{}, fmt.Errorf("index %d out of range [0, %d]", idx, len(t.Rows)-1)
+	}
+	row := t.Rows[i]
+	rows := slices.Delete(slices.Clone(t.Rows), i, i+1)
+	t.Rows = slices.Insert(rows, idx, row)
+	return t, nil
+}
+
+// Sort returns the table with its rows ordered by identifier.
+func (t Table) Sort() Table {
+	t.Rows = slices.Clone(t.Rows)
+	slices.SortStableFunc(t.Rows, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	return t
+}
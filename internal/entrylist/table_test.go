@@ -0,0 +1,227 @@
+package entrylist
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+var columns = []string{"USERS", "PASSWORDS", "DATABASES"}
+
+func testTable(t *testing.T) Table {
+	t.Helper()
+	tbl, err := Codec{Sep: ";"}.ParseTable(columns, []string{"alice;bob;carol", "pass1;pass2;pass3", "app;reports;app"})
+	if err != nil {
+		t.Fatalf("ParseTable: %v", err)
+	}
+	return tbl
+}
+
+func column(tbl Table, col int) string {
+	items := make([]string, len(tbl.Rows))
+	for i, row := range tbl.Rows {
+		items[i] = row[col]
+	}
+	return strings.Join(items, ";")
+}
+
+// ---- ParseTable / SerializeTable ----
+
+func TestParseTable_RoundTrip(t *testing.T) {
+	tbl := testTable(t)
+	if !slices.Equal(tbl.Rows[1], []string{"bob", "pass2", "reports"}) {
+		t.Errorf("Rows[1] = %q", tbl.Rows[1])
+	}
+	lists, err := Codec{Sep: ";"}.SerializeTable(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alice;bob;carol", "pass1;pass2;pass3", "app;reports;app"}; !slices.Equal(lists, want) {
+		t.Errorf("SerializeTable = %q, want %q", lists, want)
+	}
+}
+
+func TestParseTable_Errors(t *testing.T) {
+	c := Codec{Sep: ";"}
+	tests := []struct {
+		name  string
+		lists []string
+		want  string
+	}{
+		{"length mismatch", []string{"alice;bob", "pass1;pass2", "app"}, "DATABASES has 1 item(s)"},
+		{"duplicate key", []string{"alice;alice", "pass1;pass2", "a;b"}, "duplicate key"},
+		{"wrong list count", []string{"alice", "pass1"}, "2 list(s) for 3 column(s)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.ParseTable(columns, tt.lists)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseTable error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTable_TrimsAndDropsEmptyItems(t *testing.T) {
+	tbl, err := Codec{Sep: ";"}.ParseTable(columns, []string{" alice ; bob ;", "pass1;pass2", "app; reports"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(tbl.Rows[1], []string{"bob", "pass2", "reports"}) {
+		t.Errorf("Rows[1] = %q", tbl.Rows[1])
+	}
+	if tbl, err = (Codec{Sep: ";"}).ParseTable(columns, []string{"", "", " "}); err != nil || len(tbl.Rows) != 0 {
+		t.Errorf("empty lists: rows %q, err %v", tbl.Rows, err)
+	}
+}
+
+func TestParseTable_Quoted(t *testing.T) {
+	c := Codec{Sep: ";", Quote: `"`}
+	tbl, err := NewTable(columns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tbl, err = tbl.Add([]string{"alice", "p;ss", ""}); err != nil {
+		t.Fatal(err)
+	}
+	lists, err := c.SerializeTable(tbl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.ParseTable(columns, lists)
+	if err != nil {
+		t.Fatalf("ParseTable(%q): %v", lists, err)
+	}
+	if !slices.Equal(got.Rows[0], []string{"alice", "p;ss", ""}) {
+		t.Errorf("Rows[0] = %q", got.Rows[0])
+	}
+}
+
+func TestNewTable_Errors(t *testing.T) {
+	for _, cols := range [][]string{{"USERS"}, {"USERS", "USERS"}, {"USERS", ""}} {
+		if _, err := NewTable(cols...); err == nil {
+			t.Errorf("NewTable(%q): expected error", cols)
+		}
+	}
+}
+
+// ---- CheckRow ----
+
+func TestCheckRow(t *testing.T) {
+	tbl := testTable(t)
+	c := Codec{Sep: ";"}
+	if err := c.CheckRow(tbl, []string{"dave", "pass4", "app"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := c.CheckRow(tbl, []string{"dave", "pass4", "a;b"})
+	if err == nil || !strings.Contains(err.Error(), "DATABASES of entry \"dave\"") {
+		t.Errorf("error = %v, want one naming the column", err)
+	}
+	if err := c.CheckRow(tbl, []string{"dave", "pass4"}); err == nil {
+		t.Error("expected error for a short row")
+	}
+}
+
+// ---- row operations ----
+
+func TestTable_AddInsertRemove(t *testing.T) {
+	tbl := testTable(t)
+	added, err := tbl.Insert(1, []string{"dave", "pass4", "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := column(added, 0); got != "alice;dave;bob;carol" {
+		t.Errorf("after Insert: %q", got)
+	}
+	if got := column(tbl, 0); got != "alice;bob;carol" {
+		t.Errorf("Insert must not modify its input, got %q", got)
+	}
+	if _, err := tbl.Add([]string{"bob", "x", "y"}); err == nil {
+		t.Error("expected error for duplicate key")
+	}
+	if _, err := tbl.Add([]string{"dave", "x"}); err == nil {
+		t.Error("expected error for a short row")
+	}
+
+	removed, err := tbl.Remove("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := column(removed, 2); got != "app;app" {
+		t.Errorf("after Remove: %q", got)
+	}
+	if _, err := tbl.Remove("dave"); err == nil {
+		t.Error("expected error for missing key")
+	}
+}
+
+func TestTable_RemoveWhere(t *testing.T) {
+	tbl := testTable(t)
+	removed, err := tbl.RemoveWhere(2, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := column(removed, 0); got != "bob;carol" {
+		t.Errorf("RemoveWhere removed the wrong row: %q", got)
+	}
+	if _, err := tbl.RemoveWhere(1, "nope"); err == nil {
+		t.Error("expected error for missing value")
+	}
+}
+
+func TestTable_UpdateAndRename(t *testing.T) {
+	tbl := testTable(t)
+	updated, err := tbl.Update("bob", []string{"new", "billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := column(updated, 1) + "|" + column(updated, 2); got != "pass1;new;pass3|app;billing;app" {
+		t.Errorf("after Update: %q", got)
+	}
+	if column(tbl, 1) != "pass1;pass2;pass3" {
+		t.Error("Update must not modify its input")
+	}
+	if _, err := tbl.Update("bob", []string{"new"}); err == nil {
+		t.Error("expected error for missing value")
+	}
+	if _, err := tbl.Update("dave", []string{"x", "y"}); err == nil {
+		t.Error("expected error for missing key")
+	}
+
+	renamed, err := tbl.Rename("bob", "robert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := column(renamed, 0) + "|" + column(renamed, 1); got != "alice;robert;carol|pass1;pass2;pass3" {
+		t.Errorf("after Rename: %q", got)
+	}
+	if column(tbl, 0) != "alice;bob;carol" {
+		t.Error("Rename must not modify its input")
+	}
+	for _, newKey := range []string{"alice", ""} {
+		if _, err := tbl.Rename("bob", newKey); err == nil {
+			t.Errorf("Rename(bob, %q): expected error", newKey)
+		}
+	}
+	if _, err := tbl.Rename("dave", "x"); err == nil {
+		t.Error("expected error for missing key")
+	}
+}
+
+func TestTable_MoveAndSort(t *testing.T) {
+	tbl := testTable(t)
+	moved, err := tbl.Move("carol", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := column(moved, 1); got != "pass3;pass1;pass2" {
+		t.Errorf("after Move: %q", got)
+	}
+	sorted := moved.Sort()
+	if got := column(sorted, 0) + "|" + column(sorted, 2); got != "alice;bob;carol|app;reports;app" {
+		t.Errorf("after Sort: %q", got)
+	}
+	if _, err := tbl.Move("carol", 3); err == nil {
+		t.Error("expected error for out-of-range index")
+	}
+}
@@ -0,0 +1,274 @@
+// Package keygen generates cryptographic key material for Secret values:
+// asymmetric private keys as PKCS#8 PEM or OpenSSH keypairs, each with a
+// matching public key, and random symmetric keys in raw, hex, or base64.
+//
+// What to generate is described by a spec string:
+//
+//	rsa[:BITS]            RSA, PKCS#8 PEM (default 3072 bits)
+//	ecdsa[:CURVE]         ECDSA on p256 (default), p384, or p521, PKCS#8 PEM
+//	ed25519               Ed25519, PKCS#8 PEM
+//	ssh-rsa[:BITS]        RSA in OpenSSH format
+//	ssh-ecdsa[:CURVE]     ECDSA in OpenSSH format
+//	ssh-ed25519           Ed25519 in OpenSSH format
+//	bytes:N[:ENCODING]    N random bytes, base64 (default), hex, or raw
+package keygen
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Key types.
+const (
+	TypeRSA     = "rsa"
+	TypeECDSA   = "ecdsa"
+	TypeEd25519 = "ed25519"
+	TypeBytes   = "bytes"
+)
+
+// Output formats.
+const (
+	FormatPKCS8   = "pkcs8"
+	FormatOpenSSH = "openssh"
+	FormatBase64  = "base64"
+	FormatHex     = "hex"
+	FormatRaw     = "raw"
+)
+
+// Limits and defaults for spec parameters.
+const (
+	DefaultRSABits = 3072
+	MinRSABits     = 2048
+	MaxRSABits     = 8192
+	DefaultCurve   = "p256"
+	MaxBytes       = 1024
+)
+
+// Spec describes the key material to generate.
+type Spec struct {
+	Type   string
+	Format string
+	// Bits is the RSA modulus size.
+	Bits int
+	// Curve is the ECDSA curve: p256, p384, or p521.
+	Curve string
+	// Size is the number of random bytes for TypeBytes.
+	Size int
+}
+
+// Material is generated key material. Public is nil for random bytes.
+type Material struct {
+	Private []byte
+	Public  []byte
+}
+
+// ParseSpec parses a spec string such as "rsa:4096", "ssh-ed25519", or
+// "bytes:32:hex".
+func ParseSpec(spec string) (Spec, error) {
+	parts := strings.Split(strings.ToLower(spec), ":")
+	name, args := parts[0], parts[1:]
+
+	s := Spec{Format: FormatPKCS8}
+	if rest, ok := strings.CutPrefix(name, "ssh-"); ok {
+		s.Format = FormatOpenSSH
+		name = rest
+	}
+
+	tooMany := func(n int) error {
+		if len(args) > n {
+			return fmt.Errorf("key spec %q: too many parameters", spec)
+		}
+		return nil
+	}
+
+	switch name {
+	case TypeRSA:
+		s.Type, s.Bits = TypeRSA, DefaultRSABits
+		if err := tooMany(1); err != nil {
+			return Spec{}, err
+		}
+		if len(args) == 1 {
+			bits, err := strconv.Atoi(args[0])
+			if err != nil || bits < MinRSABits || bits > MaxRSABits {
+				return Spec{}, fmt.Errorf("key spec %q: RSA bits must be between %d and %d", spec, MinRSABits, MaxRSABits)
+			}
+			s.Bits = bits
+		}
+	case TypeECDSA:
+		s.Type, s.Curve = TypeECDSA, DefaultCurve
+		if err := tooMany(1); err != nil {
+			return Spec{}, err
+		}
+		if len(args) == 1 {
+			s.Curve = args[0]
+		}
+		if _, err := curve(s.Curve); err != nil {
+			return Spec{}, fmt.Errorf("key spec %q: %w", spec, err)
+		}
+	case TypeEd25519:
+		s.Type = TypeEd25519
+		if err := tooMany(0); err != nil {
+			return Spec{}, err
+		}
+	case TypeBytes:
+		if s.Format == FormatOpenSSH {
+			return Spec{}, fmt.Errorf("key spec %q: unknown key type", spec)
+		}
+		s.Type, s.Format = TypeBytes, FormatBase64
+		if len(args) == 0 {
+			return Spec{}, fmt.Errorf("key spec %q: byte count required (e.g. bytes:32)", spec)
+		}
+		if err := tooMany(2); err != nil {
+			return Spec{}, err
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 || n > MaxBytes {
+			return Spec{}, fmt.Errorf("key spec %q: byte count must be between 1 and %d", spec, MaxBytes)
+		}
+		s.Size = n
+		if len(args) == 2 {
+			switch args[1] {
+			case FormatBase64, FormatHex, FormatRaw:
+				s.Format = args[1]
+			default:
+				return Spec{}, fmt.Errorf("key spec %q: unknown encoding %q: use base64, hex, or raw", spec, args[1])
+			}
+		}
+	default:
+		return Spec{}, fmt.Errorf("key spec %q: unknown key type: use rsa, ecdsa, ed25519, ssh-rsa, ssh-ecdsa, ssh-ed25519, or bytes", spec)
+	}
+	return s, nil
+}
+
+// String returns the canonical spec string.
+func (s Spec) String() string {
+	var name string
+	switch s.Type {
+	case TypeRSA:
+		name = fmt.Sprintf("rsa:%d", s.Bits)
+	case TypeECDSA:
+		name = "ecdsa:" + s.Curve
+	case TypeBytes:
+		return fmt.Sprintf("bytes:%d:%s", s.Size, s.Format)
+	default:
+		name = s.Type
+	}
+	if s.Format == FormatOpenSSH {
+		name = "ssh-" + name
+	}
+	return name
+}
+
+// HasPublic reports whether the spec produces a public key.
+func (s Spec) HasPublic() bool {
+	return s.Type != TypeBytes
+}
+
+// Generate creates new key material. Private keys are PKCS#8 PEM with a PKIX
+// "PUBLIC KEY" PEM public key, or, for OpenSSH specs, an OpenSSH private key
+// with an authorized_keys public key line.
+func (s Spec) Generate() (*Material, error) {
+	if s.Type == TypeBytes {
+		return s.generateBytes()
+	}
+	signer, err := s.NewSigner()
+	if err != nil {
+		return nil, err
+	}
+	if s.Format == FormatOpenSSH {
+		return marshalOpenSSH(signer)
+	}
+	return MarshalPKCS8(signer)
+}
+
+// NewSigner generates a private key for an asymmetric spec.
+func (s Spec) NewSigner() (crypto.Signer, error) {
+	switch s.Type {
+	case TypeRSA:
+		return rsa.GenerateKey(rand.Reader, s.Bits)
+	case TypeECDSA:
+		c, err := curve(s.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsa.GenerateKey(c, rand.Reader)
+	case TypeEd25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("%s is not an asymmetric key type", s.Type)
+	}
+}
+
+// MarshalPKCS8 encodes key as a PKCS#8 "PRIVATE KEY" PEM block and its public
+// key as a PKIX "PUBLIC KEY" PEM block.
+func MarshalPKCS8(key crypto.Signer) (*Material, error) {
+	priv, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("marshal public key: %w", err)
+	}
+	return &Material{
+		Private: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: priv}),
+		Public:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}),
+	}, nil
+}
+
+func marshalOpenSSH(key crypto.Signer) (*Material, error) {
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		return nil, fmt.Errorf("marshal OpenSSH private key: %w", err)
+	}
+	pub, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("marshal OpenSSH public key: %w", err)
+	}
+	return &Material{
+		Private: pem.EncodeToMemory(block),
+		Public:  ssh.MarshalAuthorizedKey(pub),
+	}, nil
+}
+
+func (s Spec) generateBytes() (*Material, error) {
+	buf := make([]byte, s.Size)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	switch s.Format {
+	case FormatHex:
+		return &Material{Private: []byte(hex.EncodeToString(buf))}, nil
+	case FormatRaw:
+		return &Material{Private: buf}, nil
+	default:
+		return &Material{Private: []byte(base64.StdEncoding.EncodeToString(buf))}, nil
+	}
+}
+
+func curve(name string) (elliptic.Curve, error) {
+	switch name {
+	case "p256":
+		return elliptic.P256(), nil
+	case "p384":
+		return elliptic.P384(), nil
+	case "p521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unknown curve %q: use p256, p384, or p521", name)
+	}
+}
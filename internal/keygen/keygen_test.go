@@ -0,0 +1,122 @@
+package keygen_test
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/keygen"
+)
+
+func TestParseSpec(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"rsa", "rsa:3072"},
+		{"RSA:4096", "rsa:4096"},
+		{"ecdsa", "ecdsa:p256"},
+		{"ecdsa:p521", "ecdsa:p521"},
+		{"ed25519", "ed25519"},
+		{"ssh-ed25519", "ssh-ed25519"},
+		{"ssh-rsa:2048", "ssh-rsa:2048"},
+		{"bytes:32", "bytes:32:base64"},
+		{"bytes:16:hex", "bytes:16:hex"},
+	}
+	for _, tc := range cases {
+		spec, err := keygen.ParseSpec(tc.in)
+		if err != nil {
+			t.Errorf("ParseSpec(%q): %v", tc.in, err)
+			continue
+		}
+		if got := spec.String(); got != tc.want {
+			t.Errorf("ParseSpec(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseSpec_Errors(t *testing.T) {
+	for _, in := range []string{
+		"dsa", "rsa:1024", "rsa:abc", "ecdsa:p224", "ed25519:1",
+		"bytes", "bytes:0", "bytes:32:base32", "ssh-bytes:32",
+	} {
+		if _, err := keygen.ParseSpec(in); err == nil {
+			t.Errorf("ParseSpec(%q): expected error", in)
+		}
+	}
+}
+
+func generate(t *testing.T, spec string) *keygen.Material {
+	t.Helper()
+	s, err := keygen.ParseSpec(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := s.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestGenerate_PKCS8(t *testing.T) {
+	for _, spec := range []string{"rsa:2048", "ecdsa:p384", "ed25519"} {
+		t.Run(spec, func(t *testing.T) {
+			m := generate(t, spec)
+			block, _ := pem.Decode(m.Private)
+			if block == nil || block.Type != "PRIVATE KEY" {
+				t.Fatalf("expected PRIVATE KEY PEM, got %q", m.Private)
+			}
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			block, _ = pem.Decode(m.Public)
+			if block == nil || block.Type != "PUBLIC KEY" {
+				t.Fatalf("expected PUBLIC KEY PEM, got %q", m.Public)
+			}
+			pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signer := key.(crypto.Signer)
+			if !pub.(interface{ Equal(crypto.PublicKey) bool }).Equal(signer.Public()) {
+				t.Error("public key does not match private key")
+			}
+		})
+	}
+}
+
+func TestGenerate_OpenSSH(t *testing.T) {
+	m := generate(t, "ssh-ed25519")
+	signer, err := ssh.ParsePrivateKey(m.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(m.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pub.Marshal()) != string(signer.PublicKey().Marshal()) {
+		t.Error("public key does not match private key")
+	}
+}
+
+func TestGenerate_Bytes(t *testing.T) {
+	if m := generate(t, "bytes:32:raw"); len(m.Private) != 32 || m.Public != nil {
+		t.Errorf("raw: got %d bytes, public %v", len(m.Private), m.Public)
+	}
+	if m, _ := hex.DecodeString(string(generate(t, "bytes:16:hex").Private)); len(m) != 16 {
+		t.Errorf("hex: decoded %d bytes", len(m))
+	}
+	b64 := string(generate(t, "bytes:32").Private)
+	if m, err := base64.StdEncoding.DecodeString(b64); err != nil || len(m) != 32 || strings.TrimSpace(b64) != b64 {
+		t.Errorf("base64: %q (%v)", b64, err)
+	}
+}
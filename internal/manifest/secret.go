@@ -3,6 +3,7 @@ package manifest
 import (
 	"fmt"
 	"os"
+	"unicode/utf8"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -55,9 +56,63 @@ func ToYAML(s *corev1.Secret) ([]byte, error) {
 	return out, nil
 }
 
+// Output modes accepted by ParseEmitMode.
+const (
+	// EmitData writes every value base64-encoded under data:.
+	EmitData = "data"
+	// EmitStringData writes UTF-8 values as plain text under stringData:.
+	EmitStringData = "string-data"
+)
+
+// ParseEmitMode validates an output mode name. An empty name yields EmitData.
+func ParseEmitMode(name string) (string, error) {
+	switch name {
+	case "", EmitData:
+		return EmitData, nil
+	case EmitStringData:
+		return EmitStringData, nil
+	default:
+		return "", fmt.Errorf("unknown emit mode %q: use %s or %s", name, EmitData, EmitStringData)
+	}
+}
+
+// ToYAMLAs serialises the secret using the given emit mode.
+// In EmitStringData mode, values that are valid UTF-8 are written as readable
+// text under stringData:; any binary values stay base64-encoded under data:
+// since stringData cannot represent them. s itself is not modified.
+func ToYAMLAs(s *corev1.Secret, mode string) ([]byte, error) {
+	if mode != EmitStringData {
+		return ToYAML(s)
+	}
+
+	out := s.DeepCopy()
+	out.Data = nil
+	out.StringData = nil
+	for k, v := range s.Data {
+		if utf8.Valid(v) {
+			if out.StringData == nil {
+				out.StringData = make(map[string]string)
+			}
+			out.StringData[k] = string(v)
+			continue
+		}
+		if out.Data == nil {
+			out.Data = make(map[string][]byte)
+		}
+		out.Data[k] = v
+	}
+	return ToYAML(out)
+}
+
 // FromYAML parses a Kubernetes Secret manifest from YAML bytes.
 // Base64 values in data: are decoded automatically into []byte.
+// Entries in stringData: are merged into Data, taking precedence over data:
+// entries with the same key as the API server does; StringData is then
+// cleared so callers only ever deal with Data.
 func FromYAML(data []byte) (*corev1.Secret, error) {
+	if n := len(SplitDocuments(data)); n > 1 {
+		return nil, fmt.Errorf("expected a single Secret document, got a stream of %d documents", n)
+	}
 	var s corev1.Secret
 	if err := yaml.Unmarshal(data, &s); err != nil {
 		return nil, fmt.Errorf("parse secret YAML: %w", err)
@@ -68,6 +123,10 @@ func FromYAML(data []byte) (*corev1.Secret, error) {
 	if s.Data == nil {
 		s.Data = make(map[string][]byte)
 	}
+	for k, v := range s.StringData {
+		s.Data[k] = []byte(v)
+	}
+	s.StringData = nil
 	return &s, nil
 }
 
@@ -189,6 +189,97 @@ type: Opaque
 	}
 }
 
+func TestFromYAML_StringDataMerged(t *testing.T) {
+	yaml := `apiVersion: v1
+kind: Secret
+metadata:
+  name: s
+  namespace: default
+stringData:
+  USER: admin
+`
+	s, err := FromYAML([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(s.Data["USER"]) != "admin" {
+		t.Errorf("USER = %q, want \"admin\"", s.Data["USER"])
+	}
+	if s.StringData != nil {
+		t.Error("StringData should be cleared after merging")
+	}
+}
+
+func TestFromYAML_StringDataOverridesData(t *testing.T) {
+	yaml := `apiVersion: v1
+kind: Secret
+metadata:
+  name: s
+  namespace: default
+data:
+  PASS: b2xk
+  KEEP: a2VlcA==
+stringData:
+  PASS: new
+`
+	s, err := FromYAML([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(s.Data["PASS"]) != "new" {
+		t.Errorf("PASS = %q, want stringData value \"new\"", s.Data["PASS"])
+	}
+	if string(s.Data["KEEP"]) != "keep" {
+		t.Errorf("KEEP = %q, want \"keep\"", s.Data["KEEP"])
+	}
+}
+
+// ---- ParseEmitMode / ToYAMLAs ----
+
+func TestParseEmitMode(t *testing.T) {
+	for in, want := range map[string]string{"": EmitData, "data": EmitData, "string-data": EmitStringData} {
+		got, err := ParseEmitMode(in)
+		if err != nil {
+			t.Fatalf("ParseEmitMode(%q): unexpected error: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("ParseEmitMode(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if _, err := ParseEmitMode("stringdata"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestToYAMLAs_StringData(t *testing.T) {
+	s := NewSecret("s", "default")
+	SetPlainValue(s, "API_KEY", "hello")
+	s.Data["BIN"] = []byte{0xff, 0xfe}
+
+	out, err := ToYAMLAs(s, EmitStringData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yaml := string(out)
+	if !strings.Contains(yaml, "stringData:") || !strings.Contains(yaml, "API_KEY: hello") {
+		t.Errorf("UTF-8 value should be emitted as stringData, got:\n%s", yaml)
+	}
+	if !strings.Contains(yaml, "BIN: //4=") {
+		t.Errorf("binary value should stay base64 under data, got:\n%s", yaml)
+	}
+	if string(s.Data["API_KEY"]) != "hello" || s.StringData != nil {
+		t.Error("input secret should not be modified")
+	}
+
+	back, err := FromYAML(out)
+	if err != nil {
+		t.Fatalf("FromYAML: %v", err)
+	}
+	if string(back.Data["API_KEY"]) != "hello" || string(back.Data["BIN"]) != "\xff\xfe" {
+		t.Errorf("round-trip mismatch: %v", back.Data)
+	}
+}
+
 // ---- FromFile ----
 
 func TestFromFile_HappyPath(t *testing.T) {
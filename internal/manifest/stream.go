@@ -0,0 +1,183 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Document is one document of a multi-document YAML stream.
+// Secret is set for v1 Secret documents; all other documents (ConfigMaps,
+// SealedSecrets, comment-only blocks, ...) keep only their Raw bytes so they
+// can be written back untouched.
+type Document struct {
+	Raw    []byte
+	Secret *corev1.Secret
+}
+
+// SplitDocuments splits a YAML stream on "---" separator lines.
+// Documents that contain only whitespace are dropped.
+func SplitDocuments(data []byte) [][]byte {
+	var docs [][]byte
+	var cur bytes.Buffer
+
+	flush := func() {
+		if len(bytes.TrimSpace(cur.Bytes())) > 0 {
+			docs = append(docs, bytes.Clone(cur.Bytes()))
+		}
+		cur.Reset()
+	}
+
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if isDocumentSeparator(line) {
+			flush()
+			continue
+		}
+		cur.Write(line)
+	}
+	flush()
+	return docs
+}
+
+func isDocumentSeparator(line []byte) bool {
+	s := strings.TrimRight(string(line), " \t\r\n")
+	return s == "---" || strings.HasPrefix(s, "--- #")
+}
+
+// ParseStream parses every document in a YAML stream.
+// Secret documents are decoded with FromYAML; everything else is passed through.
+func ParseStream(data []byte) ([]Document, error) {
+	raw := SplitDocuments(data)
+	docs := make([]Document, 0, len(raw))
+	for i, r := range raw {
+		var tm metav1.TypeMeta
+		if err := yaml.Unmarshal(r, &tm); err != nil {
+			return nil, fmt.Errorf("document %d: parse YAML: %w", i+1, err)
+		}
+		doc := Document{Raw: r}
+		if tm.Kind == "Secret" && tm.APIVersion == "v1" {
+			s, err := FromYAML(r)
+			if err != nil {
+				return nil, fmt.Errorf("document %d: %w", i+1, err)
+			}
+			doc.Secret = s
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// StreamToYAML serialises docs back into a multi-document stream.
+// Secret documents whose Secret was modified are re-encoded from it; every
+// other document, including unmodified Secrets, is written exactly as it was
+// read.
+func StreamToYAML(docs []Document) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, d := range docs {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		body := d.Raw
+		if d.Secret != nil && !unmodified(d) {
+			out, err := ToYAML(d.Secret)
+			if err != nil {
+				return nil, err
+			}
+			body = out
+		}
+		buf.Write(body)
+		if len(body) > 0 && body[len(body)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// unmodified reports whether d.Secret still matches the document's Raw bytes.
+func unmodified(d Document) bool {
+	orig, err := FromYAML(d.Raw)
+	return err == nil && reflect.DeepEqual(orig, d.Secret)
+}
+
+// SpliceSecret returns stream with the Secret that has the same name and
+// namespace as s replaced by body, the serialised s, or with body appended
+// when no Secret matches. Every other document is kept byte for byte.
+func SpliceSecret(stream []byte, s *corev1.Secret, body []byte) ([]byte, error) {
+	docs, err := ParseStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	at := -1
+	for i, d := range docs {
+		if d.Secret == nil || d.Secret.Name != s.Name || d.Secret.Namespace != s.Namespace {
+			continue
+		}
+		if at >= 0 {
+			return nil, fmt.Errorf("more than one Secret %s/%s in the stream", s.Namespace, s.Name)
+		}
+		at = i
+	}
+	if at < 0 {
+		docs = append(docs, Document{Raw: body})
+	} else {
+		docs[at] = Document{Raw: body}
+	}
+	return StreamToYAML(docs)
+}
+
+// StreamFromFile reads and parses every document in a YAML file.
+func StreamFromFile(path string) ([]Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file %q: %w", path, err)
+	}
+	return ParseStream(data)
+}
+
+// Secrets returns the Secret documents of docs, in stream order.
+func Secrets(docs []Document) []*corev1.Secret {
+	var out []*corev1.Secret
+	for _, d := range docs {
+		if d.Secret != nil {
+			out = append(out, d.Secret)
+		}
+	}
+	return out
+}
+
+// Select returns the single Secret in docs matching name and namespace.
+// An empty name or namespace matches any value. It is an error for zero or
+// more than one Secret to match.
+func Select(docs []Document, name, namespace string) (*corev1.Secret, error) {
+	var matches []*corev1.Secret
+	for _, s := range Secrets(docs) {
+		if (name == "" || s.Name == name) && (namespace == "" || s.Namespace == namespace) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return nil, fmt.Errorf("no Secret matching %s", describeSelector(name, namespace))
+	default:
+		return nil, fmt.Errorf("%d Secrets match %s; narrow the selection by name and namespace",
+			len(matches), describeSelector(name, namespace))
+	}
+}
+
+func describeSelector(name, namespace string) string {
+	if name == "" {
+		name = "*"
+	}
+	if namespace == "" {
+		namespace = "*"
+	}
+	return namespace + "/" + name
+}
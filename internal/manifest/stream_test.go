@@ -0,0 +1,211 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+const testStream = `# leading comment
+apiVersion: v1
+kind: Secret
+metadata:
+  name: a
+  namespace: default
+data:
+  KEY: dmFs
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  plain: text   # keep this comment
+--- # second secret
+apiVersion: v1
+kind: Secret
+metadata:
+  name: b
+  namespace: prod
+stringData:
+  OTHER: x
+---
+`
+
+// ---- SplitDocuments ----
+
+func TestSplitDocuments(t *testing.T) {
+	docs := SplitDocuments([]byte(testStream))
+	if len(docs) != 3 {
+		t.Fatalf("want 3 documents, got %d", len(docs))
+	}
+}
+
+func TestSplitDocuments_LeadingSeparator(t *testing.T) {
+	docs := SplitDocuments([]byte("---\nkind: A\n"))
+	if len(docs) != 1 || string(docs[0]) != "kind: A\n" {
+		t.Errorf("unexpected documents: %q", docs)
+	}
+}
+
+func TestSplitDocuments_IndentedDashesNotSeparator(t *testing.T) {
+	docs := SplitDocuments([]byte("stringData:\n  KEY: |\n    ---\n    x\n"))
+	if len(docs) != 1 {
+		t.Errorf("indented --- inside a block scalar must not split, got %d docs", len(docs))
+	}
+}
+
+// ---- ParseStream / StreamToYAML ----
+
+func TestParseStream(t *testing.T) {
+	docs, err := ParseStream([]byte(testStream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secrets := Secrets(docs)
+	if len(secrets) != 2 {
+		t.Fatalf("want 2 secrets, got %d", len(secrets))
+	}
+	if secrets[0].Name != "a" || string(secrets[0].Data["KEY"]) != "val" {
+		t.Errorf("first secret = %+v", secrets[0])
+	}
+	if secrets[1].Name != "b" || string(secrets[1].Data["OTHER"]) != "x" {
+		t.Errorf("second secret = %+v", secrets[1])
+	}
+	if docs[1].Secret != nil {
+		t.Error("ConfigMap should not be parsed as a Secret")
+	}
+}
+
+func TestStreamToYAML_PassesThroughOtherDocuments(t *testing.T) {
+	docs, err := ParseStream([]byte(testStream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	SetPlainValue(docs[0].Secret, "NEW", "added")
+
+	out, err := StreamToYAML(docs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(out), "plain: text   # keep this comment") {
+		t.Errorf("non-Secret document should be untouched:\n%s", out)
+	}
+
+	back, err := ParseStream(out)
+	if err != nil {
+		t.Fatalf("re-parse: %v", err)
+	}
+	if len(back) != 3 {
+		t.Fatalf("want 3 documents after round-trip, got %d", len(back))
+	}
+	if string(back[0].Secret.Data["NEW"]) != "added" {
+		t.Error("modification to first secret was lost")
+	}
+}
+
+func TestParseStream_InvalidSecret(t *testing.T) {
+	_, err := ParseStream([]byte("apiVersion: v1\nkind: Secret\ndata:\n  K: '!!!'\n"))
+	if err == nil {
+		t.Error("expected error for invalid base64 in a Secret document")
+	}
+}
+
+func TestStreamToYAML_KeepsUnmodifiedSecrets(t *testing.T) {
+	docs, err := ParseStream([]byte(testStream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := StreamToYAML(docs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The stringData: of the second Secret would become data: if re-encoded.
+	if !strings.Contains(string(out), "# leading comment") || !strings.Contains(string(out), "stringData:\n  OTHER: x") {
+		t.Errorf("unmodified Secrets should be written as read:\n%s", out)
+	}
+}
+
+func TestSpliceSecret(t *testing.T) {
+	docs, err := ParseStream([]byte(testStream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b := docs[2].Secret
+	SetPlainValue(b, "NEW", "added")
+	body, err := ToYAML(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := SpliceSecret([]byte(testStream), b, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	back, err := ParseStream(out)
+	if err != nil {
+		t.Fatalf("re-parse: %v", err)
+	}
+	if len(back) != 3 || string(back[2].Secret.Data["NEW"]) != "added" {
+		t.Fatalf("Secret b not replaced in place:\n%s", out)
+	}
+	if !strings.Contains(string(out), "# leading comment") || !strings.Contains(string(out), "plain: text   # keep this comment") {
+		t.Errorf("other documents should be untouched:\n%s", out)
+	}
+
+	c := NewSecret("c", "default")
+	body, err = ToYAML(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = SpliceSecret([]byte(testStream), c, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	back, err = ParseStream(out)
+	if err != nil {
+		t.Fatalf("re-parse: %v", err)
+	}
+	if len(back) != 4 || back[3].Secret.Name != "c" {
+		t.Errorf("new Secret should be appended:\n%s", out)
+	}
+}
+
+// ---- Select ----
+
+func TestSelect(t *testing.T) {
+	docs, err := ParseStream([]byte(testStream))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s, err := Select(docs, "b", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Namespace != "prod" {
+		t.Errorf("Namespace = %q, want \"prod\"", s.Namespace)
+	}
+
+	s, err = Select(docs, "", "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Name != "a" {
+		t.Errorf("Name = %q, want \"a\"", s.Name)
+	}
+
+	if _, err := Select(docs, "", ""); err == nil {
+		t.Error("expected error when selection is ambiguous")
+	}
+	if _, err := Select(docs, "missing", ""); err == nil {
+		t.Error("expected error when nothing matches")
+	}
+}
+
+// ---- FromYAML ----
+
+func TestFromYAML_RejectsMultipleDocuments(t *testing.T) {
+	if _, err := FromYAML([]byte(testStream)); err == nil {
+		t.Error("expected error for a multi-document stream")
+	}
+}
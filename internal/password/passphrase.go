@@ -0,0 +1,119 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// effLargeWordlist is the EFF large wordlist (7776 words, five dice rolls
+// per word), https://www.eff.org/dice, licensed CC BY 3.0 US.
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist string
+
+var wordlist = sync.OnceValue(func() []string {
+	words := make([]string, 0, 7776)
+	sc := bufio.NewScanner(strings.NewReader(effLargeWordlist))
+	for sc.Scan() {
+		if _, word, ok := strings.Cut(sc.Text(), "\t"); ok {
+			words = append(words, word)
+		}
+	}
+	return words
+})
+
+// Wordlist returns the bundled diceware wordlist.
+func Wordlist() []string {
+	return wordlist()
+}
+
+// Passphrase generates diceware-style passphrases of Words words drawn
+// uniformly from the bundled wordlist and joined with Separator.
+type Passphrase struct {
+	Words     int
+	Separator string
+}
+
+// Check reports whether the passphrase settings are usable.
+func (p Passphrase) Check() error {
+	if p.Words <= 0 {
+		return fmt.Errorf("word count must be positive")
+	}
+	if p.Separator == "" {
+		return fmt.Errorf("separator must not be empty")
+	}
+	return nil
+}
+
+// Generate returns a random passphrase.
+func (p Passphrase) Generate() (string, error) {
+	if err := p.Check(); err != nil {
+		return "", err
+	}
+	words := wordlist()
+	out := make([]string, p.Words)
+	for i := range out {
+		j, err := randomInt(len(words))
+		if err != nil {
+			return "", err
+		}
+		out[i] = words[j]
+	}
+	phrase := strings.Join(out, p.Separator)
+	if err := p.Verify(phrase); err != nil {
+		return "", fmt.Errorf("generated value failed policy check: %w", err)
+	}
+	return phrase, nil
+}
+
+// Verify checks that value is Words wordlist words joined with Separator.
+// A few words contain a hyphen (t-shirt, yo-yo), so with a "-" separator a
+// word may span several separator-delimited parts; any split into exactly
+// Words words is accepted.
+func (p Passphrase) Verify(value string) error {
+	known := make(map[string]bool, len(wordlist()))
+	for _, w := range wordlist() {
+		known[w] = true
+	}
+	parts := strings.Split(value, p.Separator)
+
+	// counts[i] holds the word counts that parts[:i] can be split into.
+	counts := make([]map[int]bool, len(parts)+1)
+	counts[0] = map[int]bool{0: true}
+	for i := range parts {
+		if len(counts[i]) == 0 {
+			continue
+		}
+		for j := i + 1; j <= len(parts); j++ {
+			if !known[strings.Join(parts[i:j], p.Separator)] {
+				continue
+			}
+			if counts[j] == nil {
+				counts[j] = make(map[int]bool)
+			}
+			for c := range counts[i] {
+				counts[j][c+1] = true
+			}
+		}
+	}
+
+	got := counts[len(parts)]
+	if got[p.Words] {
+		return nil
+	}
+	if len(got) == 0 {
+		return fmt.Errorf("is not made of wordlist words joined with %q", p.Separator)
+	}
+	return fmt.Errorf("has %d word(s), want %d", slices.Min(slices.Collect(maps.Keys(got))), p.Words)
+}
+
+// EntropyBits returns Words × log2(wordlist size), about 12.9 bits per word.
+func (p Passphrase) EntropyBits() float64 {
+	return float64(p.Words) * math.Log2(float64(len(wordlist())))
+}
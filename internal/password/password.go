@@ -0,0 +1,220 @@
+// Package password generates random secret values under a complexity policy:
+// character passwords with per-class minimums drawn from a configurable
+// alphabet, or diceware passphrases from the bundled EFF large wordlist.
+//
+// Every generator can verify a value against its own policy and report the
+// entropy of the values it produces.
+package password
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Character classes used by the per-class minimums. Symbols are the
+// printable ASCII punctuation characters.
+const (
+	Lower   = "abcdefghijklmnopqrstuvwxyz"
+	Upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits  = "0123456789"
+	Symbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+)
+
+// Generator produces random values and can check a value against the policy
+// it generates for.
+type Generator interface {
+	Generate() (string, error)
+	Verify(value string) error
+	// EntropyBits estimates the entropy of a generated value in bits.
+	EntropyBits() float64
+}
+
+// Policy generates character passwords of Length characters drawn from
+// Alphabet, with at least the given number of characters from each class.
+type Policy struct {
+	Length   int
+	Alphabet string
+
+	MinLower   int
+	MinUpper   int
+	MinDigits  int
+	MinSymbols int
+}
+
+// Alphabet builds an alphabet from charset with every character of exclude
+// removed. Duplicate characters are dropped so that each remaining character
+// is equally likely. Only printable ASCII is accepted.
+func Alphabet(charset, exclude string) (string, error) {
+	for _, r := range charset {
+		if r <= ' ' || r > '~' {
+			return "", fmt.Errorf("character %q is not printable ASCII", r)
+		}
+	}
+	var b strings.Builder
+	seen := make(map[byte]bool, len(charset))
+	for i := 0; i < len(charset); i++ {
+		c := charset[i]
+		if seen[c] || strings.IndexByte(exclude, c) >= 0 {
+			continue
+		}
+		seen[c] = true
+		b.WriteByte(c)
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("no characters left to generate from")
+	}
+	return b.String(), nil
+}
+
+type class struct {
+	name  string
+	chars string
+	min   int
+}
+
+func (p Policy) classes() []class {
+	return []class{
+		{"lowercase", Lower, p.MinLower},
+		{"uppercase", Upper, p.MinUpper},
+		{"digit", Digits, p.MinDigits},
+		{"symbol", Symbols, p.MinSymbols},
+	}
+}
+
+// Check reports whether the policy can be satisfied: the alphabet must be
+// non-empty, contain characters of every class with a minimum, and the
+// minimums must fit in Length.
+func (p Policy) Check() error {
+	if p.Length <= 0 {
+		return fmt.Errorf("length must be positive")
+	}
+	if p.Alphabet == "" {
+		return fmt.Errorf("alphabet is empty")
+	}
+	total := 0
+	for _, c := range p.classes() {
+		if c.min < 0 {
+			return fmt.Errorf("minimum %s characters must not be negative", c.name)
+		}
+		if c.min > 0 && p.available(c) == "" {
+			return fmt.Errorf("minimum %d %s character(s) requested but the alphabet has none", c.min, c.name)
+		}
+		total += c.min
+	}
+	if total > p.Length {
+		return fmt.Errorf("character class minimums (%d) exceed the length (%d)", total, p.Length)
+	}
+	return nil
+}
+
+// available returns the characters of class c present in the alphabet.
+func (p Policy) available(c class) string {
+	var b strings.Builder
+	for i := 0; i < len(p.Alphabet); i++ {
+		if strings.IndexByte(c.chars, p.Alphabet[i]) >= 0 {
+			b.WriteByte(p.Alphabet[i])
+		}
+	}
+	return b.String()
+}
+
+// Generate returns a password satisfying the policy. The class minimums are
+// drawn first from their class, the rest from the whole alphabet, and the
+// result is shuffled so the required characters land in random positions.
+func (p Policy) Generate() (string, error) {
+	if err := p.Check(); err != nil {
+		return "", err
+	}
+	out := make([]byte, 0, p.Length)
+	for _, c := range p.classes() {
+		chars := p.available(c)
+		for range c.min {
+			ch, err := pick(chars)
+			if err != nil {
+				return "", err
+			}
+			out = append(out, ch)
+		}
+	}
+	for len(out) < p.Length {
+		ch, err := pick(p.Alphabet)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, ch)
+	}
+	for i := len(out) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		out[i], out[j] = out[j], out[i]
+	}
+
+	pw := string(out)
+	if err := p.Verify(pw); err != nil {
+		return "", fmt.Errorf("generated value failed policy check: %w", err)
+	}
+	return pw, nil
+}
+
+// Verify checks value against the policy's length, alphabet, and class
+// minimums.
+func (p Policy) Verify(value string) error {
+	if len(value) != p.Length {
+		return fmt.Errorf("length is %d, want %d", len(value), p.Length)
+	}
+	for i := 0; i < len(value); i++ {
+		if strings.IndexByte(p.Alphabet, value[i]) < 0 {
+			return fmt.Errorf("character %q is not in the allowed alphabet", rune(value[i]))
+		}
+	}
+	for _, c := range p.classes() {
+		n := 0
+		for i := 0; i < len(value); i++ {
+			if strings.IndexByte(c.chars, value[i]) >= 0 {
+				n++
+			}
+		}
+		if n < c.min {
+			return fmt.Errorf("has %d %s character(s), want at least %d", n, c.name, c.min)
+		}
+	}
+	return nil
+}
+
+// EntropyBits returns the entropy of the draws Generate makes: log2 of the
+// class size for every required character plus log2 of the alphabet size for
+// the rest. It ignores the shuffle, so it is conservative; without class
+// minimums it is exact.
+func (p Policy) EntropyBits() float64 {
+	bits := 0.0
+	rest := p.Length
+	for _, c := range p.classes() {
+		if c.min > 0 {
+			bits += float64(c.min) * math.Log2(float64(len(p.available(c))))
+			rest -= c.min
+		}
+	}
+	return bits + float64(rest)*math.Log2(float64(len(p.Alphabet)))
+}
+
+func pick(chars string) (byte, error) {
+	i, err := randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i], nil
+}
+
+// randomInt returns a uniform random integer in [0, n).
+func randomInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
@@ -0,0 +1,129 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/password"
+)
+
+func TestAlphabet(t *testing.T) {
+	got, err := password.Alphabet("aabbc0O1", "0O")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "abc1" {
+		t.Errorf("got %q, want %q", got, "abc1")
+	}
+
+	for _, tc := range []struct{ charset, exclude string }{
+		{"abc", "abc"},
+		{"ab c", ""},
+		{"abé", ""},
+	} {
+		if _, err := password.Alphabet(tc.charset, tc.exclude); err == nil {
+			t.Errorf("Alphabet(%q, %q): expected error", tc.charset, tc.exclude)
+		}
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	cases := []struct {
+		name string
+		p    password.Policy
+		want string
+	}{
+		{"zero length", password.Policy{Alphabet: "ab"}, "length"},
+		{"class missing", password.Policy{Length: 8, Alphabet: password.Lower, MinDigits: 1}, "digit"},
+		{"minimums too long", password.Policy{Length: 2, Alphabet: password.Lower + password.Upper, MinLower: 2, MinUpper: 1}, "exceed"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.p.Check()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_GenerateSatisfiesMinimums(t *testing.T) {
+	alphabet, err := password.Alphabet(password.Lower+password.Upper+password.Digits+password.Symbols, `"'\`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := password.Policy{Length: 8, Alphabet: alphabet, MinLower: 2, MinUpper: 2, MinDigits: 2, MinSymbols: 2}
+	for range 200 {
+		pw, err := p.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Verify(pw); err != nil {
+			t.Fatalf("%q: %v", pw, err)
+		}
+		if strings.ContainsAny(pw, `"'\`) {
+			t.Fatalf("%q contains an excluded character", pw)
+		}
+	}
+}
+
+func TestPolicy_Verify(t *testing.T) {
+	p := password.Policy{Length: 4, Alphabet: password.Lower + password.Digits, MinDigits: 1}
+	for value, ok := range map[string]bool{
+		"abc1":  true,
+		"abcd":  false,
+		"abc":   false,
+		"ABC1":  false,
+		"abc12": false,
+	} {
+		if err := p.Verify(value); (err == nil) != ok {
+			t.Errorf("Verify(%q) = %v, want ok=%v", value, err, ok)
+		}
+	}
+}
+
+func TestPolicy_EntropyBits(t *testing.T) {
+	p := password.Policy{Length: 10, Alphabet: password.Digits + "abcdef"}
+	if got := p.EntropyBits(); got != 40 {
+		t.Errorf("hex: got %v bits, want 40", got)
+	}
+	p.MinDigits = 10
+	if got, full := p.EntropyBits(), 40.0; got >= full {
+		t.Errorf("minimums should lower the estimate, got %v", got)
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	if n := len(password.Wordlist()); n != 7776 {
+		t.Fatalf("wordlist has %d words, want 7776", n)
+	}
+	p := password.Passphrase{Words: 6, Separator: "-"}
+	phrase, err := p.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Verify(phrase); err != nil {
+		t.Errorf("%q: %v", phrase, err)
+	}
+	if err := p.Verify("correct-horse-battery-staple-xyzzyq-zoom"); err == nil {
+		t.Error("expected unknown word to fail verification")
+	}
+	if bits := p.EntropyBits(); bits < 77 || bits > 78 {
+		t.Errorf("six words: got %v bits, want ~77.5", bits)
+	}
+}
+
+func TestPassphrase_HyphenatedWords(t *testing.T) {
+	p := password.Passphrase{Words: 2, Separator: "-"}
+	for value, ok := range map[string]bool{
+		"t-shirt-yo-yo":      true,
+		"drop-down-felt-tip": true,
+		"t-shirt":            false, // one word
+		"t-shirt-yo-yo-zoom": false,
+		"shirt-yo":           false,
+	} {
+		if err := p.Verify(value); (err == nil) != ok {
+			t.Errorf("Verify(%q) = %v, want ok=%v", value, err, ok)
+		}
+	}
+}
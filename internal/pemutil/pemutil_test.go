@@ -0,0 +1,83 @@
+package pemutil_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/certgen"
+	"github.com/pbsladek/k8s-secret-manifest/internal/keygen"
+	"github.com/pbsladek/k8s-secret-manifest/internal/pemutil"
+)
+
+func TestParseCertificates_Chain(t *testing.T) {
+	k, err := keygen.ParseSpec("ed25519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chain []byte
+	for _, cn := range []string{"leaf", "intermediate"} {
+		issued, err := certgen.Issue(certgen.Request{CommonName: cn, Key: k, Validity: time.Hour}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain = append(chain, issued.CertPEM...)
+		chain = append(chain, issued.KeyPEM...) // non-certificate blocks are skipped
+	}
+
+	certs, err := pemutil.ParseCertificates(chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 2 || certs[0].Subject.CommonName != "leaf" || certs[1].Subject.CommonName != "intermediate" {
+		t.Errorf("got %d certificate(s), want leaf then intermediate", len(certs))
+	}
+
+	if _, err := pemutil.ParseCertificates([]byte("not pem")); err == nil || !strings.Contains(err.Error(), "no PEM CERTIFICATE") {
+		t.Errorf("expected missing-block error, got %v", err)
+	}
+}
+
+func TestParsePrivateKey_Formats(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, block := range map[string]*pem.Block{
+		"pkcs1": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		"sec1":  {Type: "EC PRIVATE KEY", Bytes: sec1},
+		"pkcs8": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		data := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{0}}), pem.EncodeToMemory(block)...)
+		if _, err := pemutil.ParsePrivateKey(data); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	bad := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("junk")})
+	if _, err := pemutil.ParsePrivateKey(bad); err == nil || !strings.Contains(err.Error(), "parse ec private key") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+	if _, err := pemutil.ParsePrivateKey(nil); err == nil {
+		t.Error("expected missing-block error")
+	}
+}
@@ -0,0 +1,256 @@
+// Package policy evaluates team conventions, declared as rules in a YAML
+// policy file, against Secret manifests.
+//
+// A policy file looks like:
+//
+//	rules:
+//	  - id: prod-immutable
+//	    severity: error
+//	    match:
+//	      namespaces: ["prod", "prod-*"]
+//	    requireImmutable: true
+//	  - id: strong-passwords
+//	    severity: warning
+//	    minEntropy:
+//	      keys: ["*PASSWORD*", "*_SECRET"]
+//	      bits: 64
+//
+// Every violation becomes a validate.Issue whose Code is the rule ID and
+// whose Severity is the rule's severity, so policy findings can be reported
+// and filtered alongside the built-in checks.
+package policy
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/validate"
+)
+
+// Policy is a set of rules loaded from a policy file.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule is one convention. Match selects the Secrets it applies to; every
+// requirement that is set is checked.
+type Rule struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	// Severity is "error" (the default) or "warning".
+	Severity string `json:"severity,omitempty"`
+	Match    Match  `json:"match,omitempty"`
+
+	RequireLabels      []string     `json:"requireLabels,omitempty"`
+	RequireAnnotations []string     `json:"requireAnnotations,omitempty"`
+	ForbiddenKeys      []string     `json:"forbiddenKeys,omitempty"`
+	RequireImmutable   bool         `json:"requireImmutable,omitempty"`
+	MinEntropy         *EntropyRule `json:"minEntropy,omitempty"`
+}
+
+// Match scopes a rule. Each list holds glob patterns (path.Match syntax); an
+// empty list matches everything, and a Secret must match every non-empty list.
+type Match struct {
+	Namespaces []string `json:"namespaces,omitempty"`
+	Names      []string `json:"names,omitempty"`
+	Types      []string `json:"types,omitempty"`
+}
+
+// EntropyRule requires values of keys matching Keys to carry at least Bits
+// of estimated entropy (see EntropyBits).
+type EntropyRule struct {
+	Keys []string `json:"keys"`
+	Bits float64  `json:"bits"`
+}
+
+// Load reads and parses a policy file.
+func Load(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read policy %q: %w", file, err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("policy %q: %w", file, err)
+	}
+	return p, nil
+}
+
+// Parse parses policy YAML. Unknown fields are rejected so that a misspelt
+// requirement fails loudly instead of silently never matching.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, fmt.Errorf("parse YAML: %w", err)
+	}
+
+	seen := make(map[string]bool, len(p.Rules))
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.ID == "" {
+			return nil, fmt.Errorf("rule %d: id is required", i+1)
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("rule %q: duplicate id", r.ID)
+		}
+		seen[r.ID] = true
+		if err := r.check(); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.ID, err)
+		}
+	}
+	return &p, nil
+}
+
+func (r *Rule) check() error {
+	switch r.Severity {
+	case "":
+		r.Severity = validate.SeverityError
+	case validate.SeverityError, validate.SeverityWarning:
+	default:
+		return fmt.Errorf("severity %q: use error or warning", r.Severity)
+	}
+
+	patterns := [][]string{r.Match.Namespaces, r.Match.Names, r.Match.Types, r.ForbiddenKeys}
+	if r.MinEntropy != nil {
+		if r.MinEntropy.Bits <= 0 {
+			return fmt.Errorf("minEntropy.bits must be positive")
+		}
+		if len(r.MinEntropy.Keys) == 0 {
+			return fmt.Errorf("minEntropy.keys must not be empty")
+		}
+		patterns = append(patterns, r.MinEntropy.Keys)
+	}
+	for _, list := range patterns {
+		for _, pat := range list {
+			if _, err := path.Match(pat, ""); err != nil {
+				return fmt.Errorf("pattern %q: %w", pat, err)
+			}
+		}
+	}
+
+	if len(r.RequireLabels) == 0 && len(r.RequireAnnotations) == 0 && len(r.ForbiddenKeys) == 0 &&
+		!r.RequireImmutable && r.MinEntropy == nil {
+		return fmt.Errorf("no requirements: set requireLabels, requireAnnotations, forbiddenKeys, requireImmutable, or minEntropy")
+	}
+	return nil
+}
+
+// Check evaluates every rule that matches s and returns its violations, in
+// rule order.
+func (p *Policy) Check(s *corev1.Secret) []validate.Issue {
+	var issues []validate.Issue
+	for _, r := range p.Rules {
+		if r.matches(s) {
+			issues = append(issues, r.evaluate(s)...)
+		}
+	}
+	return issues
+}
+
+func (r Rule) matches(s *corev1.Secret) bool {
+	return matchAny(r.Match.Namespaces, s.Namespace) &&
+		matchAny(r.Match.Names, s.Name) &&
+		matchAny(r.Match.Types, secretType(s))
+}
+
+func (r Rule) evaluate(s *corev1.Secret) []validate.Issue {
+	var issues []validate.Issue
+	add := func(key, format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		if r.Description != "" {
+			msg += " (" + r.Description + ")"
+		}
+		issues = append(issues, validate.Issue{
+			Severity: r.Severity,
+			Code:     r.ID,
+			Key:      key,
+			Message:  fmt.Sprintf("policy %s: %s", r.ID, msg),
+		})
+	}
+
+	for _, l := range r.RequireLabels {
+		if _, ok := s.Labels[l]; !ok {
+			add("", "label %q is required", l)
+		}
+	}
+	for _, a := range r.RequireAnnotations {
+		if _, ok := s.Annotations[a]; !ok {
+			add("", "annotation %q is required", a)
+		}
+	}
+
+	keys := slices.Sorted(maps.Keys(s.Data))
+	if len(r.ForbiddenKeys) > 0 {
+		for _, k := range keys {
+			if matchAny(r.ForbiddenKeys, k) {
+				add(k, "data key %q is forbidden", k)
+			}
+		}
+	}
+
+	if r.RequireImmutable && (s.Immutable == nil || !*s.Immutable) {
+		add("", "secret must be immutable")
+	}
+
+	if e := r.MinEntropy; e != nil {
+		for _, k := range keys {
+			if !matchAny(e.Keys, k) {
+				continue
+			}
+			if bits := EntropyBits(string(s.Data[k])); bits < e.Bits {
+				add(k, "value of %q has an estimated %.0f bits of entropy, below the required %.0f", k, bits, e.Bits)
+			}
+		}
+	}
+	return issues
+}
+
+// EntropyBits estimates the entropy of s as its length times the Shannon
+// entropy of its character distribution. Repeated or predictable characters
+// lower the estimate; it is a heuristic for catching weak values, not a
+// guarantee of strength.
+func EntropyBits(s string) float64 {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range runes {
+		counts[r]++
+	}
+	n := float64(len(runes))
+	var perChar float64
+	for _, c := range counts {
+		p := float64(c) / n
+		perChar -= p * math.Log2(p)
+	}
+	return perChar * n
+}
+
+// matchAny reports whether value matches one of patterns; an empty pattern
+// list matches everything.
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pat := range patterns {
+		if ok, _ := path.Match(pat, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func secretType(s *corev1.Secret) string {
+	if s.Type == "" {
+		return string(corev1.SecretTypeOpaque)
+	}
+	return string(s.Type)
+}
@@ -0,0 +1,151 @@
+package policy_test
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/policy"
+	"github.com/pbsladek/k8s-secret-manifest/internal/validate"
+)
+
+func makeSecret(name, namespace string, data map[string]string) *corev1.Secret {
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{},
+	}
+	for k, v := range data {
+		s.Data[k] = []byte(v)
+	}
+	return s
+}
+
+func mustParse(t *testing.T, doc string) *policy.Policy {
+	t.Helper()
+	p, err := policy.Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return p
+}
+
+func codes(issues []validate.Issue) []string {
+	out := make([]string, len(issues))
+	for i, issue := range issues {
+		out[i] = issue.Code
+	}
+	return out
+}
+
+func TestParse_Errors(t *testing.T) {
+	cases := []struct {
+		name, doc, want string
+	}{
+		{"unknown field", "rules:\n- id: r\n  requireLabel: [a]\n", "requireLabel"},
+		{"missing id", "rules:\n- requireImmutable: true\n", "id is required"},
+		{"duplicate id", "rules:\n- id: r\n  requireImmutable: true\n- id: r\n  requireImmutable: true\n", "duplicate"},
+		{"bad severity", "rules:\n- id: r\n  severity: fatal\n  requireImmutable: true\n", "severity"},
+		{"bad pattern", "rules:\n- id: r\n  forbiddenKeys: ['[']\n", "pattern"},
+		{"no requirements", "rules:\n- id: r\n  match: {namespaces: [prod]}\n", "no requirements"},
+		{"entropy without bits", "rules:\n- id: r\n  minEntropy: {keys: ['*']}\n", "bits"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := policy.Parse([]byte(tc.doc))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestCheck_Scope(t *testing.T) {
+	p := mustParse(t, `rules:
+- id: prod-immutable
+  match:
+    namespaces: ["prod-*"]
+    names: ["db-*"]
+    types: ["Opaque"]
+  requireImmutable: true
+`)
+	cases := []struct {
+		name, secret, namespace string
+		want                    int
+	}{
+		{"match", "db-main", "prod-eu", 1},
+		{"other namespace", "db-main", "staging", 0},
+		{"other name", "cache", "prod-eu", 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := p.Check(makeSecret(tc.secret, tc.namespace, nil))
+			if len(issues) != tc.want {
+				t.Errorf("expected %d issue(s), got %v", tc.want, issues)
+			}
+		})
+	}
+
+	immutable := true
+	s := makeSecret("db-main", "prod-eu", nil)
+	s.Immutable = &immutable
+	if issues := p.Check(s); len(issues) != 0 {
+		t.Errorf("immutable secret should pass, got %v", issues)
+	}
+}
+
+func TestCheck_Requirements(t *testing.T) {
+	p := mustParse(t, `rules:
+- id: owner
+  requireLabels: [owner]
+  requireAnnotations: [team.example.com/contact]
+- id: no-debug
+  severity: warning
+  forbiddenKeys: ["DEBUG_*"]
+`)
+	s := makeSecret("s", "default", map[string]string{"DEBUG_TOKEN": "x", "KEY": "v"})
+	issues := p.Check(s)
+
+	got := strings.Join(codes(issues), ",")
+	if got != "owner,owner,no-debug" {
+		t.Fatalf("unexpected codes %q: %v", got, issues)
+	}
+	if !issues[0].IsError() || issues[2].IsError() {
+		t.Errorf("severity not taken from rule: %v", issues)
+	}
+	if issues[2].Key != "DEBUG_TOKEN" {
+		t.Errorf("expected key DEBUG_TOKEN, got %q", issues[2].Key)
+	}
+}
+
+func TestCheck_MinEntropy(t *testing.T) {
+	p := mustParse(t, `rules:
+- id: strong
+  minEntropy:
+    keys: ["*PASSWORD"]
+    bits: 64
+`)
+	s := makeSecret("s", "default", map[string]string{
+		"DB_PASSWORD":    "aaaaaaaaaaaaaaaaaaaaaaaa",
+		"ADMIN_PASSWORD": "q8Zr-2mXv!Lp7Tn4Wc9Yb3Ke",
+		"USERNAME":       "a",
+	})
+	issues := p.Check(s)
+	if len(issues) != 1 || issues[0].Key != "DB_PASSWORD" {
+		t.Errorf("expected only DB_PASSWORD to fail, got %v", issues)
+	}
+}
+
+func TestEntropyBits(t *testing.T) {
+	if got := policy.EntropyBits(""); got != 0 {
+		t.Errorf("empty: got %v", got)
+	}
+	if got := policy.EntropyBits("aaaa"); got != 0 {
+		t.Errorf("repeated: got %v", got)
+	}
+	if got := policy.EntropyBits("abcd"); got != 8 {
+		t.Errorf("four distinct: got %v, want 8", got)
+	}
+}
@@ -0,0 +1,167 @@
+// Package report defines the machine-readable documents printed by list,
+// show, diff, validate, stale, and list-entries when --output-format is json or yaml.
+//
+// The field names below are a stable interface for pipelines and policy
+// checks: fields may be added, but existing fields are not renamed, removed,
+// or given a different meaning. Lists are always present (possibly empty) and
+// sorted by key so that output is deterministic.
+package report
+
+import "github.com/pbsladek/k8s-secret-manifest/internal/validate"
+
+// Diff kinds, used for both whole Secrets and individual keys.
+const (
+	KindAdded     = "added"
+	KindRemoved   = "removed"
+	KindChanged   = "changed"
+	KindUnchanged = "unchanged"
+)
+
+// Value describes a decoded data value. Plain is set only when the value was
+// revealed (--reveal or --reveal-key); otherwise only the size in bytes and
+// the short SHA-256 fingerprint are included.
+type Value struct {
+	Size        int     `json:"size"`
+	Fingerprint string  `json:"sha256"`
+	Plain       *string `json:"value,omitempty"`
+}
+
+// DataEntry is one data key and its value.
+type DataEntry struct {
+	Key string `json:"key"`
+	Value
+}
+
+// Source identifies a Secret and the file it was read from.
+type Source struct {
+	File      string `json:"file"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// KeyInfo is one data key as printed by list.
+type KeyInfo struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+// SecretKeys is the list entry for one Secret.
+type SecretKeys struct {
+	Source
+	Type string    `json:"type"`
+	Keys []KeyInfo `json:"keys"`
+}
+
+// List is the document printed by list.
+type List struct {
+	Secrets []SecretKeys `json:"secrets"`
+}
+
+// SecretView is the show entry for one Secret.
+type SecretView struct {
+	Source
+	Type        string            `json:"type"`
+	Immutable   bool              `json:"immutable"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Data        []DataEntry       `json:"data"`
+}
+
+// Show is the document printed by show.
+type Show struct {
+	Secrets []SecretView `json:"secrets"`
+}
+
+// FieldChange is a difference in a metadata field (name, namespace, type).
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// KeyChange is the difference for one data key. From is absent for added
+// keys and To for removed keys.
+type KeyChange struct {
+	Key  string `json:"key"`
+	Kind string `json:"kind"`
+	From *Value `json:"from,omitempty"`
+	To   *Value `json:"to,omitempty"`
+}
+
+// SecretDiff compares one pair of Secrets. Kind is added or removed when the
+// Secret exists on only one side; FromFile or ToFile is then empty.
+type SecretDiff struct {
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	Kind      string        `json:"kind"`
+	FromFile  string        `json:"fromFile,omitempty"`
+	ToFile    string        `json:"toFile,omitempty"`
+	Metadata  []FieldChange `json:"metadata"`
+	Changes   []KeyChange   `json:"changes"`
+}
+
+// Diff is the document printed by diff.
+type Diff struct {
+	Secrets []SecretDiff `json:"secrets"`
+}
+
+// Finding is a validate.Issue located in a Secret.
+type Finding struct {
+	Source
+	validate.Issue
+}
+
+// Validation is the document printed by validate.
+type Validation struct {
+	Valid    bool      `json:"valid"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Issues   []Finding `json:"issues"`
+}
+
+// KeyRotation is the rotation state of one data key as printed by stale.
+// LastRotated and Due are RFC 3339 timestamps, absent when the key was never
+// stamped; MaxAge is absent when the key has none.
+type KeyRotation struct {
+	Source
+	Key         string `json:"key"`
+	LastRotated string `json:"lastRotated,omitempty"`
+	Count       int    `json:"rotationCount"`
+	MaxAge      string `json:"maxAge,omitempty"`
+	Due         string `json:"due,omitempty"`
+	Stale       bool   `json:"stale"`
+}
+
+// Staleness is the document printed by stale.
+type Staleness struct {
+	Stale int           `json:"stale"`
+	Keys  []KeyRotation `json:"keys"`
+}
+
+// ListEntry is one index position of a paired index list as printed by
+// list-entries. Missing names the list, "key" or "value", that is too short
+// to reach Index.
+type ListEntry struct {
+	Index int    `json:"index"`
+	Key   string `json:"key"`
+	Value
+	Missing string `json:"missing,omitempty"`
+}
+
+// EntryProblem is an inconsistency in a paired index list.
+type EntryProblem struct {
+	Kind    string `json:"kind"`
+	Index   int    `json:"index"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+// EntryList is the document printed by list-entries. Entries are in list
+// order rather than sorted, since position is what pairs them.
+type EntryList struct {
+	Source
+	KeysKey   string         `json:"entriesKey"`
+	ValuesKey string         `json:"entriesVal"`
+	Entries   []ListEntry    `json:"entries"`
+	Problems  []EntryProblem `json:"problems"`
+}
@@ -0,0 +1,185 @@
+// Package rotation records when Secret data keys were last changed, in
+// per-key annotations, and reports keys that are older than their maximum
+// age.
+//
+// For a data key KEY the annotations are:
+//
+//	k8s-secret-manifest.io/last-rotated.KEY    RFC 3339 UTC timestamp
+//	k8s-secret-manifest.io/rotation-count.KEY  number of recorded rotations
+//	k8s-secret-manifest.io/max-age.KEY         optional, e.g. 90d or 36h
+package rotation
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Annotation key prefixes; the data key is appended.
+const (
+	Prefix         = "k8s-secret-manifest.io/"
+	LastRotatedKey = Prefix + "last-rotated."
+	CountKey       = Prefix + "rotation-count."
+	MaxAgeKey      = Prefix + "max-age."
+)
+
+// Status is the rotation state of one data key.
+type Status struct {
+	Key string
+	// LastRotated is zero when the key has never been stamped.
+	LastRotated time.Time
+	Count       int
+	// MaxAge is zero when the key has no maximum age.
+	MaxAge time.Duration
+}
+
+// Age returns how long ago the key was last rotated.
+func (st Status) Age(now time.Time) time.Duration {
+	return now.Sub(st.LastRotated)
+}
+
+// Stale reports whether the key has a maximum age and is older than it, or
+// has never been stamped.
+func (st Status) Stale(now time.Time) bool {
+	if st.MaxAge == 0 {
+		return false
+	}
+	return st.LastRotated.IsZero() || st.Age(now) > st.MaxAge
+}
+
+// Due returns when the key must next be rotated, or the zero time when it
+// has no maximum age or was never stamped.
+func (st Status) Due() time.Time {
+	if st.MaxAge == 0 || st.LastRotated.IsZero() {
+		return time.Time{}
+	}
+	return st.LastRotated.Add(st.MaxAge)
+}
+
+// Stamp records that key was rotated at now: it sets the last-rotated
+// timestamp and increments the rotation count. A positive maxAge also sets
+// the key's maximum age; zero leaves any existing one in place.
+func Stamp(s *corev1.Secret, key string, now time.Time, maxAge time.Duration) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if s.Annotations == nil {
+		s.Annotations = make(map[string]string)
+	}
+	count, _ := strconv.Atoi(s.Annotations[CountKey+key])
+	s.Annotations[LastRotatedKey+key] = now.UTC().Format(time.RFC3339)
+	s.Annotations[CountKey+key] = strconv.Itoa(count + 1)
+	if maxAge > 0 {
+		s.Annotations[MaxAgeKey+key] = FormatMaxAge(maxAge)
+	}
+	return nil
+}
+
+// Forget removes the rotation annotations of key, e.g. when it is deleted.
+func Forget(s *corev1.Secret, key string) {
+	delete(s.Annotations, LastRotatedKey+key)
+	delete(s.Annotations, CountKey+key)
+	delete(s.Annotations, MaxAgeKey+key)
+	if len(s.Annotations) == 0 {
+		s.Annotations = nil
+	}
+}
+
+// Statuses returns the rotation state of every data key that has rotation
+// annotations, sorted by key. defaultMaxAge applies to keys without their
+// own maximum age; zero means none. Malformed annotations are errors.
+func Statuses(s *corev1.Secret, defaultMaxAge time.Duration) ([]Status, error) {
+	byKey := make(map[string]*Status)
+	get := func(key string) *Status {
+		if st, ok := byKey[key]; ok {
+			return st
+		}
+		st := &Status{Key: key, MaxAge: defaultMaxAge}
+		byKey[key] = st
+		return st
+	}
+
+	for _, ann := range slices.Sorted(maps.Keys(s.Annotations)) {
+		val := s.Annotations[ann]
+		switch {
+		case strings.HasPrefix(ann, LastRotatedKey):
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return nil, fmt.Errorf("annotation %s: %w", ann, err)
+			}
+			get(strings.TrimPrefix(ann, LastRotatedKey)).LastRotated = t
+		case strings.HasPrefix(ann, CountKey):
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("annotation %s: %q is not a rotation count", ann, val)
+			}
+			get(strings.TrimPrefix(ann, CountKey)).Count = n
+		case strings.HasPrefix(ann, MaxAgeKey):
+			d, err := ParseMaxAge(val)
+			if err != nil {
+				return nil, fmt.Errorf("annotation %s: %w", ann, err)
+			}
+			get(strings.TrimPrefix(ann, MaxAgeKey)).MaxAge = d
+		}
+	}
+
+	// A default maximum age also covers data keys that were never stamped.
+	if defaultMaxAge > 0 {
+		for key := range s.Data {
+			get(key)
+		}
+	}
+
+	out := make([]Status, 0, len(byKey))
+	for _, key := range slices.Sorted(maps.Keys(byKey)) {
+		out = append(out, *byKey[key])
+	}
+	return out, nil
+}
+
+// ParseMaxAge parses a maximum age: a number of days with a "d" suffix
+// (e.g. "90d") or a Go duration (e.g. "36h").
+func ParseMaxAge(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid max age %q: use e.g. 90d or 36h", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid max age %q: use e.g. 90d or 36h", s)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid max age %q: must be positive", s)
+	}
+	return d, nil
+}
+
+// FormatMaxAge formats d as whole days when it is a multiple of a day, and
+// as a Go duration otherwise.
+func FormatMaxAge(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
+
+// checkKey rejects data keys that cannot be embedded in an annotation key:
+// the longest prefix plus the key must be a valid qualified name.
+func checkKey(key string) error {
+	if errs := k8svalidation.IsQualifiedName(CountKey + key); len(errs) > 0 {
+		return fmt.Errorf("key %q cannot be tracked in an annotation: %s", key, strings.Join(errs, "; "))
+	}
+	return nil
+}
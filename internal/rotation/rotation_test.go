@@ -0,0 +1,141 @@
+package rotation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestStampIncrementsCount(t *testing.T) {
+	s := &corev1.Secret{Data: map[string][]byte{"KEY": []byte("v")}}
+	if err := Stamp(s, "KEY", now.Add(-time.Hour), 90*24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := Stamp(s, "KEY", now, 0); err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := Statuses(s, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("got %d statuses, want 1", len(statuses))
+	}
+	st := statuses[0]
+	if st.Count != 2 || !st.LastRotated.Equal(now) || st.MaxAge != 90*24*time.Hour {
+		t.Errorf("unexpected status %+v", st)
+	}
+	if s.Annotations[MaxAgeKey+"KEY"] != "90d" {
+		t.Errorf("max-age annotation = %q, want 90d", s.Annotations[MaxAgeKey+"KEY"])
+	}
+}
+
+func TestStampRejectsUntrackableKey(t *testing.T) {
+	s := &corev1.Secret{}
+	long := strings.Repeat("a", 60)
+	for _, key := range []string{"tls.crt", "a_b-c"} {
+		if err := Stamp(s, key, now, 0); err != nil {
+			t.Errorf("Stamp(%q): %v", key, err)
+		}
+	}
+	if err := Stamp(s, long, now, 0); err == nil {
+		t.Error("expected an error for a key that cannot form an annotation name")
+	}
+}
+
+func TestStale(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name string
+		st   Status
+		want bool
+	}{
+		{"no max age", Status{LastRotated: now.Add(-1000 * day)}, false},
+		{"within max age", Status{LastRotated: now.Add(-10 * day), MaxAge: 30 * day}, false},
+		{"past max age", Status{LastRotated: now.Add(-31 * day), MaxAge: 30 * day}, true},
+		{"never stamped", Status{MaxAge: 30 * day}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.st.Stale(now); got != tt.want {
+				t.Errorf("Stale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusesDefaultMaxAge(t *testing.T) {
+	s := &corev1.Secret{
+		Data: map[string][]byte{"A": nil, "B": nil},
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			LastRotatedKey + "A": "2026-02-01T00:00:00Z",
+			CountKey + "A":       "1",
+			MaxAgeKey + "A":      "7d",
+		}},
+	}
+	statuses, err := Statuses(s, 30*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("got %d statuses, want 2", len(statuses))
+	}
+	if a := statuses[0]; a.Key != "A" || a.MaxAge != 7*24*time.Hour || !a.Stale(now) {
+		t.Errorf("A: unexpected status %+v", a)
+	}
+	if b := statuses[1]; b.Key != "B" || !b.LastRotated.IsZero() || !b.Stale(now) {
+		t.Errorf("B: unexpected status %+v", b)
+	}
+}
+
+func TestStatusesMalformed(t *testing.T) {
+	for _, ann := range []map[string]string{
+		{LastRotatedKey + "A": "yesterday"},
+		{CountKey + "A": "-1"},
+		{MaxAgeKey + "A": "0d"},
+	} {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: ann}}
+		if _, err := Statuses(s, 0); err == nil {
+			t.Errorf("expected an error for %v", ann)
+		}
+	}
+}
+
+func TestForget(t *testing.T) {
+	s := &corev1.Secret{}
+	_ = Stamp(s, "A", now, time.Hour)
+	Forget(s, "A")
+	if s.Annotations != nil {
+		t.Errorf("annotations not removed: %v", s.Annotations)
+	}
+}
+
+func TestParseMaxAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"0d", 0, true},
+		{"-5d", 0, true},
+		{"soon", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMaxAge(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseMaxAge(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+	if got := FormatMaxAge(36 * time.Hour); got != "36h0m0s" {
+		t.Errorf("FormatMaxAge(36h) = %q", got)
+	}
+}
@@ -0,0 +1,462 @@
+// Package sealedsecret implements the Bitnami Sealed Secrets encryption scheme
+// natively, so secrets can be sealed offline without the kubeseal binary.
+//
+// Each data value is encrypted with a fresh random 32-byte session key using
+// AES-256-GCM. The session key itself is encrypted with the controller's RSA
+// public key using RSA-OAEP (SHA-256). The ciphertext layout is:
+//
+//	uint16 (big-endian) length of the RSA ciphertext
+//	RSA-OAEP(session key)
+//	AES-GCM(value)
+//
+// The OAEP label binds the ciphertext to its scope: "namespace/name" for
+// strict, "namespace" for namespace-wide, and empty for cluster-wide.
+package sealedsecret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// APIVersion and Kind identify a SealedSecret resource.
+const (
+	APIVersion = "bitnami.com/v1alpha1"
+	Kind       = "SealedSecret"
+)
+
+// Annotations that mark a secret (or its sealed counterpart) as relaxed scope.
+const (
+	AnnotationNamespaceWide = "sealedsecrets.bitnami.com/namespace-wide"
+	AnnotationClusterWide   = "sealedsecrets.bitnami.com/cluster-wide"
+)
+
+const sessionKeyBytes = 32
+
+// Scope determines which metadata the ciphertext is bound to.
+type Scope int
+
+const (
+	// ScopeStrict binds ciphertext to both the name and namespace.
+	ScopeStrict Scope = iota
+	// ScopeNamespaceWide binds ciphertext to the namespace only.
+	ScopeNamespaceWide
+	// ScopeClusterWide does not bind ciphertext to any metadata.
+	ScopeClusterWide
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeNamespaceWide:
+		return "namespace-wide"
+	case ScopeClusterWide:
+		return "cluster-wide"
+	default:
+		return "strict"
+	}
+}
+
+// ParseScope converts a scope name as accepted by kubeseal --scope.
+// An empty string yields ScopeStrict.
+func ParseScope(name string) (Scope, error) {
+	switch strings.ToLower(name) {
+	case "", "strict":
+		return ScopeStrict, nil
+	case "namespace-wide":
+		return ScopeNamespaceWide, nil
+	case "cluster-wide":
+		return ScopeClusterWide, nil
+	default:
+		return ScopeStrict, fmt.Errorf("unknown scope %q: use strict, namespace-wide, or cluster-wide", name)
+	}
+}
+
+// ScopeFromAnnotations returns the scope requested by the sealed-secrets
+// annotations, defaulting to ScopeStrict.
+func ScopeFromAnnotations(annotations map[string]string) Scope {
+	if annotations[AnnotationClusterWide] == "true" {
+		return ScopeClusterWide
+	}
+	if annotations[AnnotationNamespaceWide] == "true" {
+		return ScopeNamespaceWide
+	}
+	return ScopeStrict
+}
+
+// EncryptionLabel returns the OAEP label for the given scope.
+func EncryptionLabel(namespace, name string, scope Scope) []byte {
+	switch scope {
+	case ScopeClusterWide:
+		return []byte("")
+	case ScopeNamespaceWide:
+		return []byte(namespace)
+	default:
+		return []byte(namespace + "/" + name)
+	}
+}
+
+// SealedSecret mirrors the bitnami.com/v1alpha1 SealedSecret resource.
+type SealedSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec Spec `json:"spec"`
+}
+
+// Spec is the spec of a SealedSecret.
+type Spec struct {
+	Template      Template          `json:"template,omitempty"`
+	EncryptedData map[string]string `json:"encryptedData"`
+}
+
+// Template is the metadata and type applied to the Secret the controller
+// creates when it unseals the resource.
+type Template struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Type      corev1.SecretType `json:"type,omitempty"`
+	Immutable *bool             `json:"immutable,omitempty"`
+}
+
+// HybridEncrypt encrypts plaintext for pub, binding it to label.
+func HybridEncrypt(rnd io.Reader, pub *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, sessionKeyBytes)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, fmt.Errorf("generate session key: %w", err)
+	}
+
+	gcm, err := newGCM(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, pub, sessionKey, label)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt session key: %w", err)
+	}
+
+	out := make([]byte, 2, 2+len(rsaCiphertext)+len(plaintext)+gcm.Overhead())
+	binary.BigEndian.PutUint16(out, uint16(len(rsaCiphertext)))
+	out = append(out, rsaCiphertext...)
+
+	// Each session key is used exactly once, so a zero nonce is safe.
+	zeroNonce := make([]byte, gcm.NonceSize())
+	return gcm.Seal(out, zeroNonce, plaintext, nil), nil
+}
+
+// HybridDecrypt reverses HybridEncrypt using priv and the same label.
+func HybridDecrypt(priv *rsa.PrivateKey, ciphertext, label []byte) ([]byte, error) {
+	if len(ciphertext) < 2 {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	rsaLen := int(binary.BigEndian.Uint16(ciphertext))
+	if len(ciphertext) < 2+rsaLen {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	rsaCiphertext := ciphertext[2 : 2+rsaLen]
+	aesCiphertext := ciphertext[2+rsaLen:]
+
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), nil, priv, rsaCiphertext, label)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt session key: %w", err)
+	}
+
+	gcm, err := newGCM(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	zeroNonce := make([]byte, gcm.NonceSize())
+	plaintext, err := gcm.Open(nil, zeroNonce, aesCiphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init AES: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("init GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// Seal encrypts every data value of s and returns the SealedSecret.
+// The secret's labels, annotations, type, and immutable flag are carried over
+// to the template. Scope annotations are added when scope is not strict.
+func Seal(s *corev1.Secret, pub *rsa.PublicKey, scope Scope) (*SealedSecret, error) {
+	if s.Name == "" && scope != ScopeClusterWide {
+		return nil, fmt.Errorf("secret name is required for %s scope", scope)
+	}
+	if s.Namespace == "" && scope != ScopeClusterWide {
+		return nil, fmt.Errorf("secret namespace is required for %s scope", scope)
+	}
+
+	ss := &SealedSecret{
+		TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: Kind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.Name,
+			Namespace: s.Namespace,
+		},
+		Spec: Spec{
+			Template: Template{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        s.Name,
+					Namespace:   s.Namespace,
+					Labels:      maps.Clone(s.Labels),
+					Annotations: maps.Clone(s.Annotations),
+				},
+				Type:      s.Type,
+				Immutable: s.Immutable,
+			},
+			EncryptedData: make(map[string]string, len(s.Data)),
+		},
+	}
+
+	if key := scopeAnnotation(scope); key != "" {
+		ss.Annotations = map[string]string{key: "true"}
+		if ss.Spec.Template.Annotations == nil {
+			ss.Spec.Template.Annotations = make(map[string]string)
+		}
+		ss.Spec.Template.Annotations[key] = "true"
+	}
+
+	label := EncryptionLabel(s.Namespace, s.Name, scope)
+	for k, v := range s.Data {
+		ct, err := HybridEncrypt(rand.Reader, pub, v, label)
+		if err != nil {
+			return nil, fmt.Errorf("seal key %q: %w", k, err)
+		}
+		ss.Spec.EncryptedData[k] = base64.StdEncoding.EncodeToString(ct)
+	}
+	return ss, nil
+}
+
+// ToYAML serialises a SealedSecret to YAML.
+func ToYAML(ss *SealedSecret) ([]byte, error) {
+	out, err := yaml.Marshal(ss)
+	if err != nil {
+		return nil, fmt.Errorf("serialize sealed secret: %w", err)
+	}
+	return out, nil
+}
+
+// FromYAML parses a SealedSecret manifest from YAML bytes.
+func FromYAML(data []byte) (*SealedSecret, error) {
+	var ss SealedSecret
+	if err := yaml.Unmarshal(data, &ss); err != nil {
+		return nil, fmt.Errorf("parse sealed secret YAML: %w", err)
+	}
+	if ss.Kind != Kind || ss.APIVersion != APIVersion {
+		return nil, fmt.Errorf("expected apiVersion=%s kind=%s, got apiVersion=%s kind=%s",
+			APIVersion, Kind, ss.APIVersion, ss.Kind)
+	}
+	return &ss, nil
+}
+
+// Unseal decrypts every value in ss.Spec.EncryptedData and returns the plain
+// Secret the controller would create. Each key in keys is tried in turn, so a
+// full controller key backup (which may hold several rotated keys) can be
+// passed directly. The scope is taken from the SealedSecret's annotations.
+func Unseal(ss *SealedSecret, keys []*rsa.PrivateKey) (*corev1.Secret, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no private keys provided")
+	}
+
+	name := ss.Spec.Template.Name
+	if name == "" {
+		name = ss.Name
+	}
+	namespace := ss.Spec.Template.Namespace
+	if namespace == "" {
+		namespace = ss.Namespace
+	}
+
+	scope := ScopeFromAnnotations(ss.Annotations)
+	if scope == ScopeStrict {
+		scope = ScopeFromAnnotations(ss.Spec.Template.Annotations)
+	}
+	label := EncryptionLabel(namespace, name, scope)
+
+	s := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      maps.Clone(ss.Spec.Template.Labels),
+			Annotations: maps.Clone(ss.Spec.Template.Annotations),
+		},
+		Type:      ss.Spec.Template.Type,
+		Immutable: ss.Spec.Template.Immutable,
+		Data:      make(map[string][]byte, len(ss.Spec.EncryptedData)),
+	}
+	if s.Type == "" {
+		s.Type = corev1.SecretTypeOpaque
+	}
+	for k, v := range ss.Spec.EncryptedData {
+		ct, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: decode ciphertext: %w", k, err)
+		}
+		pt, err := decryptWithAny(keys, ct, label)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w (scope %s)", k, err, scope)
+		}
+		s.Data[k] = pt
+	}
+	return s, nil
+}
+
+func decryptWithAny(keys []*rsa.PrivateKey, ciphertext, label []byte) ([]byte, error) {
+	var lastErr error
+	for _, priv := range keys {
+		pt, err := HybridDecrypt(priv, ciphertext, label)
+		if err == nil {
+			return pt, nil
+		}
+		lastErr = err
+	}
+	if len(keys) > 1 {
+		return nil, fmt.Errorf("no matching private key among %d: %w", len(keys), lastErr)
+	}
+	return nil, lastErr
+}
+
+// ParsePrivateKeys extracts RSA private keys from either PEM data (PKCS#1 or
+// PKCS#8) or a controller key backup: a Secret, or a List of Secrets, whose
+// tls.key entries hold the PEM keys.
+func ParsePrivateKeys(data []byte) ([]*rsa.PrivateKey, error) {
+	if keys, err := parsePrivateKeyPEM(data); err != nil || len(keys) > 0 {
+		return keys, err
+	}
+
+	var backup struct {
+		Kind  string            `json:"kind"`
+		Data  map[string][]byte `json:"data"`
+		Items []corev1.Secret   `json:"items"`
+	}
+	if err := yaml.Unmarshal(data, &backup); err != nil {
+		return nil, fmt.Errorf("parse key backup YAML: %w", err)
+	}
+
+	var pemBlobs [][]byte
+	switch backup.Kind {
+	case "Secret":
+		pemBlobs = append(pemBlobs, backup.Data[corev1.TLSPrivateKeyKey])
+	case "List", "SecretList":
+		for _, item := range backup.Items {
+			pemBlobs = append(pemBlobs, item.Data[corev1.TLSPrivateKeyKey])
+		}
+	default:
+		return nil, fmt.Errorf("no PEM private key found and input is not a Secret or List (kind %q)", backup.Kind)
+	}
+
+	var keys []*rsa.PrivateKey
+	for _, blob := range pemBlobs {
+		parsed, err := parsePrivateKeyPEM(blob)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, parsed...)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("key backup contains no %s entries", corev1.TLSPrivateKeyKey)
+	}
+	return keys, nil
+}
+
+func parsePrivateKeyPEM(data []byte) ([]*rsa.PrivateKey, error) {
+	var keys []*rsa.PrivateKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return keys, nil
+		}
+
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parse RSA private key: %w", err)
+			}
+			keys = append(keys, key)
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parse private key: %w", err)
+			}
+			rsaKey, ok := key.(*rsa.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("expected an RSA private key, got %T", key)
+			}
+			keys = append(keys, rsaKey)
+		}
+	}
+}
+
+// ParsePublicKey extracts an RSA public key from PEM data containing either
+// an X.509 certificate (as fetched with kubeseal --fetch-cert) or a PKIX
+// public key.
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no CERTIFICATE or PUBLIC KEY PEM block found")
+		}
+
+		var pub any
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parse certificate: %w", err)
+			}
+			pub = cert.PublicKey
+		case "PUBLIC KEY":
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parse public key: %w", err)
+			}
+			pub = key
+		default:
+			continue
+		}
+
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("expected an RSA public key, got %T", pub)
+		}
+		return rsaPub, nil
+	}
+}
+
+func scopeAnnotation(scope Scope) string {
+	switch scope {
+	case ScopeNamespaceWide:
+		return AnnotationNamespaceWide
+	case ScopeClusterWide:
+		return AnnotationClusterWide
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,425 @@
+package sealedsecret
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var testKey *rsa.PrivateKey
+
+func init() {
+	var err error
+	testKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func testSecret() *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod", Labels: map[string]string{"app": "db"}},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"PASSWORD": []byte("hunter2"), "USER": []byte("admin")},
+	}
+}
+
+// ---- ParseScope ----
+
+func TestParseScope(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want Scope
+	}{
+		{"", ScopeStrict},
+		{"strict", ScopeStrict},
+		{"namespace-wide", ScopeNamespaceWide},
+		{"Cluster-Wide", ScopeClusterWide},
+	} {
+		got, err := ParseScope(tc.in)
+		if err != nil {
+			t.Fatalf("ParseScope(%q): unexpected error: %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Errorf("ParseScope(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseScope_Unknown(t *testing.T) {
+	if _, err := ParseScope("global"); err == nil {
+		t.Error("expected error for unknown scope")
+	}
+}
+
+func TestScopeFromAnnotations(t *testing.T) {
+	if got := ScopeFromAnnotations(nil); got != ScopeStrict {
+		t.Errorf("nil annotations: got %v, want strict", got)
+	}
+	if got := ScopeFromAnnotations(map[string]string{AnnotationNamespaceWide: "true"}); got != ScopeNamespaceWide {
+		t.Errorf("got %v, want namespace-wide", got)
+	}
+	if got := ScopeFromAnnotations(map[string]string{AnnotationClusterWide: "true"}); got != ScopeClusterWide {
+		t.Errorf("got %v, want cluster-wide", got)
+	}
+}
+
+// ---- EncryptionLabel ----
+
+func TestEncryptionLabel(t *testing.T) {
+	if got := string(EncryptionLabel("prod", "db", ScopeStrict)); got != "prod/db" {
+		t.Errorf("strict label = %q, want \"prod/db\"", got)
+	}
+	if got := string(EncryptionLabel("prod", "db", ScopeNamespaceWide)); got != "prod" {
+		t.Errorf("namespace-wide label = %q, want \"prod\"", got)
+	}
+	if got := string(EncryptionLabel("prod", "db", ScopeClusterWide)); got != "" {
+		t.Errorf("cluster-wide label = %q, want empty", got)
+	}
+}
+
+// ---- HybridEncrypt / HybridDecrypt ----
+
+func TestHybridRoundTrip(t *testing.T) {
+	label := []byte("prod/db")
+	ct, err := HybridEncrypt(rand.Reader, &testKey.PublicKey, []byte("hunter2"), label)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	pt, err := HybridDecrypt(testKey, ct, label)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if string(pt) != "hunter2" {
+		t.Errorf("got %q, want \"hunter2\"", pt)
+	}
+}
+
+func TestHybridRoundTrip_EmptyPlaintext(t *testing.T) {
+	ct, err := HybridEncrypt(rand.Reader, &testKey.PublicKey, nil, nil)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	pt, err := HybridDecrypt(testKey, ct, nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if len(pt) != 0 {
+		t.Errorf("got %q, want empty", pt)
+	}
+}
+
+func TestHybridDecrypt_WrongLabel(t *testing.T) {
+	ct, err := HybridEncrypt(rand.Reader, &testKey.PublicKey, []byte("x"), []byte("prod/db"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if _, err := HybridDecrypt(testKey, ct, []byte("prod/other")); err == nil {
+		t.Error("expected error when decrypting with a different label")
+	}
+}
+
+func TestHybridDecrypt_Truncated(t *testing.T) {
+	if _, err := HybridDecrypt(testKey, []byte{0x01}, nil); err == nil {
+		t.Error("expected error for truncated ciphertext")
+	}
+	if _, err := HybridDecrypt(testKey, []byte{0x01, 0x00, 0xff}, nil); err == nil {
+		t.Error("expected error for truncated RSA section")
+	}
+}
+
+// ---- Seal ----
+
+func TestSeal_StrictRoundTrip(t *testing.T) {
+	ss, err := Seal(testSecret(), &testKey.PublicKey, ScopeStrict)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if ss.APIVersion != APIVersion || ss.Kind != Kind {
+		t.Errorf("unexpected TypeMeta: %+v", ss.TypeMeta)
+	}
+	if ss.Spec.Template.Name != "db" || ss.Spec.Template.Namespace != "prod" {
+		t.Errorf("template metadata not carried over: %+v", ss.Spec.Template.ObjectMeta)
+	}
+	if ss.Spec.Template.Labels["app"] != "db" {
+		t.Error("template labels not carried over")
+	}
+	if len(ss.Annotations) != 0 {
+		t.Errorf("strict scope should not add annotations, got %v", ss.Annotations)
+	}
+
+	for k, want := range testSecret().Data {
+		ct, err := base64.StdEncoding.DecodeString(ss.Spec.EncryptedData[k])
+		if err != nil {
+			t.Fatalf("%s: decode: %v", k, err)
+		}
+		pt, err := HybridDecrypt(testKey, ct, []byte("prod/db"))
+		if err != nil {
+			t.Fatalf("%s: decrypt: %v", k, err)
+		}
+		if string(pt) != string(want) {
+			t.Errorf("%s = %q, want %q", k, pt, want)
+		}
+	}
+}
+
+func TestSeal_ClusterWideAnnotations(t *testing.T) {
+	ss, err := Seal(testSecret(), &testKey.PublicKey, ScopeClusterWide)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if ss.Annotations[AnnotationClusterWide] != "true" {
+		t.Error("cluster-wide annotation missing on SealedSecret")
+	}
+	if ss.Spec.Template.Annotations[AnnotationClusterWide] != "true" {
+		t.Error("cluster-wide annotation missing on template")
+	}
+	ct, _ := base64.StdEncoding.DecodeString(ss.Spec.EncryptedData["USER"])
+	if _, err := HybridDecrypt(testKey, ct, nil); err != nil {
+		t.Errorf("cluster-wide ciphertext should decrypt with empty label: %v", err)
+	}
+}
+
+func TestSeal_DoesNotMutateInputAnnotations(t *testing.T) {
+	s := testSecret()
+	s.Annotations = map[string]string{"owner": "me"}
+	if _, err := Seal(s, &testKey.PublicKey, ScopeNamespaceWide); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if len(s.Annotations) != 1 {
+		t.Errorf("input annotations were modified: %v", s.Annotations)
+	}
+}
+
+func TestSeal_StrictRequiresName(t *testing.T) {
+	s := testSecret()
+	s.Name = ""
+	if _, err := Seal(s, &testKey.PublicKey, ScopeStrict); err == nil {
+		t.Error("expected error for strict scope without a name")
+	}
+}
+
+func TestToYAML_Shape(t *testing.T) {
+	ss, err := Seal(testSecret(), &testKey.PublicKey, ScopeStrict)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	out, err := ToYAML(ss)
+	if err != nil {
+		t.Fatalf("ToYAML: %v", err)
+	}
+	for _, want := range []string{"apiVersion: bitnami.com/v1alpha1", "kind: SealedSecret", "encryptedData:", "template:"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("YAML missing %q:\n%s", want, out)
+		}
+	}
+
+	var back SealedSecret
+	if err := yaml.Unmarshal(out, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(back.Spec.EncryptedData) != 2 {
+		t.Errorf("want 2 encrypted keys after round-trip, got %d", len(back.Spec.EncryptedData))
+	}
+}
+
+// ---- ParsePublicKey ----
+
+func TestParsePublicKey_Certificate(t *testing.T) {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &testKey.PublicKey, testKey)
+	if err != nil {
+		t.Fatalf("create cert: %v", err)
+	}
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	pub, err := ParsePublicKey(pemData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !pub.Equal(&testKey.PublicKey) {
+		t.Error("parsed key does not match")
+	}
+}
+
+func TestParsePublicKey_PKIX(t *testing.T) {
+	der, err := x509.MarshalPKIXPublicKey(&testKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	pub, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !pub.Equal(&testKey.PublicKey) {
+		t.Error("parsed key does not match")
+	}
+}
+
+func TestParsePublicKey_NoPEM(t *testing.T) {
+	if _, err := ParsePublicKey([]byte("not a pem")); err == nil {
+		t.Error("expected error for non-PEM input")
+	}
+}
+
+// ---- FromYAML / Unseal ----
+
+func TestUnseal_RoundTripAllScopes(t *testing.T) {
+	for _, scope := range []Scope{ScopeStrict, ScopeNamespaceWide, ScopeClusterWide} {
+		t.Run(scope.String(), func(t *testing.T) {
+			ss, err := Seal(testSecret(), &testKey.PublicKey, scope)
+			if err != nil {
+				t.Fatalf("seal: %v", err)
+			}
+			out, err := ToYAML(ss)
+			if err != nil {
+				t.Fatalf("ToYAML: %v", err)
+			}
+			parsed, err := FromYAML(out)
+			if err != nil {
+				t.Fatalf("FromYAML: %v", err)
+			}
+			s, err := Unseal(parsed, []*rsa.PrivateKey{testKey})
+			if err != nil {
+				t.Fatalf("unseal: %v", err)
+			}
+			if s.Name != "db" || s.Namespace != "prod" || s.Kind != "Secret" {
+				t.Errorf("unexpected metadata: %s/%s kind=%s", s.Namespace, s.Name, s.Kind)
+			}
+			if string(s.Data["PASSWORD"]) != "hunter2" || string(s.Data["USER"]) != "admin" {
+				t.Errorf("unexpected data: %v", s.Data)
+			}
+		})
+	}
+}
+
+func TestUnseal_StrictRejectsRename(t *testing.T) {
+	ss, err := Seal(testSecret(), &testKey.PublicKey, ScopeStrict)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	ss.Name = "other"
+	ss.Spec.Template.Name = "other"
+	if _, err := Unseal(ss, []*rsa.PrivateKey{testKey}); err == nil {
+		t.Error("expected strict-scoped ciphertext to fail after renaming")
+	}
+}
+
+func TestUnseal_NamespaceWideAllowsRename(t *testing.T) {
+	ss, err := Seal(testSecret(), &testKey.PublicKey, ScopeNamespaceWide)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	ss.Name = "other"
+	ss.Spec.Template.Name = "other"
+	s, err := Unseal(ss, []*rsa.PrivateKey{testKey})
+	if err != nil {
+		t.Fatalf("namespace-wide ciphertext should survive a rename: %v", err)
+	}
+	if s.Name != "other" {
+		t.Errorf("Name = %q, want \"other\"", s.Name)
+	}
+}
+
+func TestUnseal_TriesEveryKey(t *testing.T) {
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	ss, err := Seal(testSecret(), &testKey.PublicKey, ScopeStrict)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if _, err := Unseal(ss, []*rsa.PrivateKey{other, testKey}); err != nil {
+		t.Fatalf("unseal should succeed with the matching key second: %v", err)
+	}
+	if _, err := Unseal(ss, []*rsa.PrivateKey{other}); err == nil {
+		t.Error("expected error with only a non-matching key")
+	}
+}
+
+func TestFromYAML_WrongKind(t *testing.T) {
+	if _, err := FromYAML([]byte("apiVersion: v1\nkind: Secret\n")); err == nil {
+		t.Error("expected error for a plain Secret")
+	}
+}
+
+// ---- ParsePrivateKeys ----
+
+func TestParsePrivateKeys_PKCS1(t *testing.T) {
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(testKey)})
+	keys, err := ParsePrivateKeys(pemData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || !keys[0].Equal(testKey) {
+		t.Error("parsed key does not match")
+	}
+}
+
+func TestParsePrivateKeys_PKCS8(t *testing.T) {
+	der, err := x509.MarshalPKCS8PrivateKey(testKey)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	keys, err := ParsePrivateKeys(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || !keys[0].Equal(testKey) {
+		t.Error("parsed key does not match")
+	}
+}
+
+func TestParsePrivateKeys_BackupList(t *testing.T) {
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(testKey)})
+	backup := "apiVersion: v1\nkind: List\nitems:\n" +
+		"- apiVersion: v1\n  kind: Secret\n  type: kubernetes.io/tls\n" +
+		"  metadata:\n    name: sealed-secrets-key1\n    namespace: kube-system\n" +
+		"  data:\n    tls.key: " + base64.StdEncoding.EncodeToString(pemKey) + "\n"
+
+	keys, err := ParsePrivateKeys([]byte(backup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || !keys[0].Equal(testKey) {
+		t.Error("parsed key does not match")
+	}
+}
+
+func TestParsePrivateKeys_BackupSecret(t *testing.T) {
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(testKey)})
+	backup := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: k\n" +
+		"data:\n  tls.key: " + base64.StdEncoding.EncodeToString(pemKey) + "\n"
+
+	keys, err := ParsePrivateKeys([]byte(backup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("want 1 key, got %d", len(keys))
+	}
+}
+
+func TestParsePrivateKeys_Garbage(t *testing.T) {
+	if _, err := ParsePrivateKeys([]byte("kind: ConfigMap\n")); err == nil {
+		t.Error("expected error for input without keys")
+	}
+}
@@ -0,0 +1,469 @@
+// Package sops encrypts and decrypts Kubernetes Secret manifests in the SOPS
+// file format using age (X25519) recipients, so the output can be consumed by
+// sops itself and by Flux's SOPS decryption.
+//
+// A random 256-bit data key encrypts every leaf value whose path matches the
+// encrypted_regex with AES-256-GCM (32-byte IV, the key path as additional
+// data). The data key is age-encrypted to each recipient and stored, armored,
+// in the sops: metadata block together with a MAC: the SHA-512 of all leaf
+// values in document order, itself encrypted with the data key using the
+// lastmodified timestamp as additional data.
+package sops
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	yaml "go.yaml.in/yaml/v2"
+)
+
+const (
+	// Version is the sops format version written to the metadata block.
+	Version = "3.8.1"
+
+	// DefaultEncryptedRegex limits encryption to the Secret payload so that
+	// apiVersion, kind, and metadata stay readable, as recommended for Flux.
+	DefaultEncryptedRegex = `^(data|stringData)$`
+
+	// defaultUnencryptedSuffix is sops' default when no regex is configured.
+	defaultUnencryptedSuffix = "_unencrypted"
+
+	metadataKey = "sops"
+	dataKeySize = 32
+	ivSize      = 32
+)
+
+var encRe = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// AgeStanza is one entry of the sops.age metadata list.
+type AgeStanza struct {
+	Recipient string `yaml:"recipient"`
+	Enc       string `yaml:"enc"`
+}
+
+type metadata struct {
+	Age               []AgeStanza `yaml:"age"`
+	LastModified      string      `yaml:"lastmodified"`
+	MAC               string      `yaml:"mac"`
+	EncryptedRegex    string      `yaml:"encrypted_regex,omitempty"`
+	UnencryptedSuffix string      `yaml:"unencrypted_suffix,omitempty"`
+	Version           string      `yaml:"version"`
+}
+
+// Key is an unwrapped sops data key together with the metadata needed to
+// re-encrypt a file for the same recipients.
+type Key struct {
+	dataKey           []byte
+	age               []AgeStanza
+	encryptedRegex    string
+	unencryptedSuffix string
+}
+
+// NewKey generates a fresh data key and wraps it for each age recipient
+// (age1... strings). encryptedRegex selects which paths are encrypted; empty
+// means DefaultEncryptedRegex.
+func NewKey(recipients []string, encryptedRegex string) (*Key, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one age recipient is required")
+	}
+	if encryptedRegex == "" {
+		encryptedRegex = DefaultEncryptedRegex
+	}
+	if _, err := regexp.Compile(encryptedRegex); err != nil {
+		return nil, fmt.Errorf("encrypted regex: %w", err)
+	}
+
+	k := &Key{dataKey: make([]byte, dataKeySize), encryptedRegex: encryptedRegex}
+	if _, err := io.ReadFull(rand.Reader, k.dataKey); err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+
+	for _, r := range recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(r))
+		if err != nil {
+			return nil, fmt.Errorf("age recipient %q: %w", r, err)
+		}
+		var buf bytes.Buffer
+		aw := armor.NewWriter(&buf)
+		w, err := age.Encrypt(aw, recipient)
+		if err != nil {
+			return nil, fmt.Errorf("wrap data key for %s: %w", r, err)
+		}
+		if _, err := w.Write(k.dataKey); err != nil {
+			return nil, fmt.Errorf("wrap data key for %s: %w", r, err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("wrap data key for %s: %w", r, err)
+		}
+		if err := aw.Close(); err != nil {
+			return nil, fmt.Errorf("wrap data key for %s: %w", r, err)
+		}
+		k.age = append(k.age, AgeStanza{Recipient: recipient.String(), Enc: buf.String()})
+	}
+	return k, nil
+}
+
+// Recipients returns the age recipients the data key is wrapped for.
+func (k *Key) Recipients() []string {
+	out := make([]string, len(k.age))
+	for i, a := range k.age {
+		out[i] = a.Recipient
+	}
+	return out
+}
+
+// IsEncrypted reports whether data is a YAML document carrying a sops
+// metadata block.
+func IsEncrypted(data []byte) bool {
+	var probe struct {
+		Sops *struct {
+			MAC string `yaml:"mac"`
+		} `yaml:"sops"`
+	}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Sops != nil && probe.Sops.MAC != ""
+}
+
+// Encrypt encrypts a plain YAML document with k and appends the sops metadata
+// block. Key order is preserved.
+func (k *Key) Encrypt(plain []byte) ([]byte, error) {
+	var tree yaml.MapSlice
+	if err := yaml.Unmarshal(plain, &tree); err != nil {
+		return nil, fmt.Errorf("parse YAML: %w", err)
+	}
+	for _, item := range tree {
+		if item.Key == metadataKey {
+			return nil, fmt.Errorf("document is already sops-encrypted")
+		}
+	}
+
+	hash := sha512.New()
+	encrypted, err := k.walk(tree, nil, func(v any, path []string, encrypt bool) (any, error) {
+		if b, ok := leafBytes(v); ok {
+			hash.Write(b)
+		}
+		if !encrypt {
+			return v, nil
+		}
+		return encryptValue(v, k.dataKey, aad(path))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lastModified := time.Now().UTC().Format(time.RFC3339)
+	mac, err := encryptValue(fmt.Sprintf("%X", hash.Sum(nil)), k.dataKey, lastModified)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := metadata{
+		Age:               k.age,
+		LastModified:      lastModified,
+		MAC:               mac.(string),
+		EncryptedRegex:    k.encryptedRegex,
+		UnencryptedSuffix: k.unencryptedSuffix,
+		Version:           Version,
+	}
+	out := append(encrypted.(yaml.MapSlice), yaml.MapItem{Key: metadataKey, Value: meta})
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("serialize encrypted YAML: %w", err)
+	}
+	return data, nil
+}
+
+// Decrypt unwraps the data key with one of identities, decrypts every
+// encrypted value, verifies the MAC, and returns the plain YAML document
+// (without the sops block) along with the Key for re-encryption.
+func Decrypt(data []byte, identities []age.Identity) ([]byte, *Key, error) {
+	var tree yaml.MapSlice
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, nil, fmt.Errorf("parse YAML: %w", err)
+	}
+
+	var metaRaw any
+	body := make(yaml.MapSlice, 0, len(tree))
+	for _, item := range tree {
+		if item.Key == metadataKey {
+			metaRaw = item.Value
+			continue
+		}
+		body = append(body, item)
+	}
+	if metaRaw == nil {
+		return nil, nil, fmt.Errorf("document has no sops metadata")
+	}
+	metaYAML, err := yaml.Marshal(metaRaw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read sops metadata: %w", err)
+	}
+	var meta metadata
+	if err := yaml.Unmarshal(metaYAML, &meta); err != nil {
+		return nil, nil, fmt.Errorf("read sops metadata: %w", err)
+	}
+
+	k := &Key{age: meta.Age, encryptedRegex: meta.EncryptedRegex, unencryptedSuffix: meta.UnencryptedSuffix}
+	if k.encryptedRegex != "" {
+		if _, err := regexp.Compile(k.encryptedRegex); err != nil {
+			return nil, nil, fmt.Errorf("sops encrypted_regex: %w", err)
+		}
+	}
+	if k.dataKey, err = unwrapDataKey(meta.Age, identities); err != nil {
+		return nil, nil, err
+	}
+
+	hash := sha512.New()
+	decrypted, err := k.walk(body, nil, func(v any, path []string, encrypt bool) (any, error) {
+		if encrypt {
+			var err error
+			if v, err = decryptValue(v, k.dataKey, aad(path)); err != nil {
+				return nil, fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+			}
+		}
+		if b, ok := leafBytes(v); ok {
+			hash.Write(b)
+		}
+		return v, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mac, err := decryptValue(meta.MAC, k.dataKey, meta.LastModified)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypt MAC: %w", err)
+	}
+	if want := fmt.Sprintf("%X", hash.Sum(nil)); mac != want {
+		return nil, nil, fmt.Errorf("MAC mismatch: file has been modified outside sops")
+	}
+
+	out, err := yaml.Marshal(decrypted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("serialize decrypted YAML: %w", err)
+	}
+	return out, k, nil
+}
+
+// ParseIdentities reads age identities (AGE-SECRET-KEY-1... lines) from an
+// identity file's contents.
+func ParseIdentities(data []byte) ([]age.Identity, error) {
+	ids, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse age identities: %w", err)
+	}
+	return ids, nil
+}
+
+func unwrapDataKey(stanzas []AgeStanza, identities []age.Identity) ([]byte, error) {
+	if len(stanzas) == 0 {
+		return nil, fmt.Errorf("sops metadata has no age recipients")
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("no age identities provided")
+	}
+	for _, s := range stanzas {
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(s.Enc)), identities...)
+		if err != nil {
+			continue
+		}
+		key, err := io.ReadAll(r)
+		if err != nil || len(key) != dataKeySize {
+			continue
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("none of the provided age identities can decrypt the data key (recipients: %s)",
+		strings.Join((&Key{age: stanzas}).Recipients(), ", "))
+}
+
+// walk visits every leaf in document order, replacing it with fn's result.
+// encrypt reports whether the leaf's path is selected for encryption.
+func (k *Key) walk(v any, path []string, fn func(v any, path []string, encrypt bool) (any, error)) (any, error) {
+	switch t := v.(type) {
+	case yaml.MapSlice:
+		out := make(yaml.MapSlice, len(t))
+		for i, item := range t {
+			key := fmt.Sprint(item.Key)
+			val, err := k.walk(item.Value, append(path[:len(path):len(path)], key), fn)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = yaml.MapItem{Key: item.Key, Value: val}
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(t))
+		for i, item := range t {
+			val, err := k.walk(item, path, fn)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = val
+		}
+		return out, nil
+	case nil:
+		return nil, nil
+	default:
+		return fn(v, path, k.shouldEncrypt(path))
+	}
+}
+
+func (k *Key) shouldEncrypt(path []string) bool {
+	if k.encryptedRegex != "" {
+		re := regexp.MustCompile(k.encryptedRegex)
+		for _, p := range path {
+			if re.MatchString(p) {
+				return true
+			}
+		}
+		return false
+	}
+	suffix := k.unencryptedSuffix
+	if suffix == "" {
+		suffix = defaultUnencryptedSuffix
+	}
+	for _, p := range path {
+		if strings.HasSuffix(p, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+func aad(path []string) string {
+	return strings.Join(path, ":") + ":"
+}
+
+// leafBytes converts a scalar to the bytes sops feeds into the MAC.
+func leafBytes(v any) ([]byte, bool) {
+	switch t := v.(type) {
+	case string:
+		return []byte(t), true
+	case int:
+		return []byte(strconv.Itoa(t)), true
+	case int64:
+		return []byte(strconv.FormatInt(t, 10)), true
+	case uint64:
+		return []byte(strconv.FormatUint(t, 10)), true
+	case float64:
+		return []byte(strconv.FormatFloat(t, 'f', -1, 64)), true
+	case bool:
+		if t {
+			return []byte("True"), true
+		}
+		return []byte("False"), true
+	default:
+		return nil, false
+	}
+}
+
+func encryptValue(v any, key []byte, additionalData string) (any, error) {
+	var typ string
+	switch v.(type) {
+	case string:
+		typ = "str"
+		if v == "" {
+			// sops leaves empty strings unencrypted.
+			return "", nil
+		}
+	case int, int64, uint64:
+		typ = "int"
+	case float64:
+		typ = "float"
+	case bool:
+		typ = "bool"
+	default:
+		return nil, fmt.Errorf("cannot encrypt value of type %T", v)
+	}
+	plain, _ := leafBytes(v)
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, ivSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, fmt.Errorf("generate IV: %w", err)
+	}
+	sealed := gcm.Seal(nil, iv, plain, []byte(additionalData))
+	ct, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(ct),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag),
+		typ), nil
+}
+
+func decryptValue(v any, key []byte, additionalData string) (any, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected an encrypted string, got %T", v)
+	}
+	if s == "" {
+		return "", nil
+	}
+	m := encRe.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("value is not in sops ENC[...] format")
+	}
+	ct, err1 := base64.StdEncoding.DecodeString(m[1])
+	iv, err2 := base64.StdEncoding.DecodeString(m[2])
+	tag, err3 := base64.StdEncoding.DecodeString(m[3])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, fmt.Errorf("malformed ENC[...] value")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init AES: %w", err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, fmt.Errorf("init GCM: %w", err)
+	}
+	plain, err := gcm.Open(nil, iv, append(ct, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt value: authentication failed")
+	}
+
+	switch m[4] {
+	case "str":
+		return string(plain), nil
+	case "int":
+		return strconv.Atoi(string(plain))
+	case "float":
+		return strconv.ParseFloat(string(plain), 64)
+	case "bool":
+		return strconv.ParseBool(strings.ToLower(string(plain)))
+	default:
+		return nil, fmt.Errorf("unsupported encrypted type %q", m[4])
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init AES: %w", err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, ivSize)
+	if err != nil {
+		return nil, fmt.Errorf("init GCM: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,182 @@
+package sops
+
+import (
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	yaml "go.yaml.in/yaml/v2"
+)
+
+const plainSecret = `apiVersion: v1
+kind: Secret
+metadata:
+  name: app
+  namespace: default
+type: Opaque
+data:
+  API_KEY: c2VjcmV0
+  EMPTY: ""
+`
+
+func newIdentity(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func encryptFor(t *testing.T, plain string, ids ...*age.X25519Identity) []byte {
+	t.Helper()
+	var recipients []string
+	for _, id := range ids {
+		recipients = append(recipients, id.Recipient().String())
+	}
+	k, err := NewKey(recipients, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := k.Encrypt([]byte(plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enc
+}
+
+func TestEncrypt_Format(t *testing.T) {
+	id := newIdentity(t)
+	enc := encryptFor(t, plainSecret, id)
+
+	var doc struct {
+		APIVersion string            `yaml:"apiVersion"`
+		Metadata   map[string]string `yaml:"metadata"`
+		Data       map[string]string `yaml:"data"`
+		Sops       metadata          `yaml:"sops"`
+	}
+	if err := yaml.Unmarshal(enc, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.APIVersion != "v1" || doc.Metadata["name"] != "app" {
+		t.Errorf("metadata should stay in plain text:\n%s", enc)
+	}
+	if !strings.HasPrefix(doc.Data["API_KEY"], "ENC[AES256_GCM,data:") || !strings.HasSuffix(doc.Data["API_KEY"], ",type:str]") {
+		t.Errorf("API_KEY not encrypted: %q", doc.Data["API_KEY"])
+	}
+	if doc.Data["EMPTY"] != "" {
+		t.Errorf("empty value should stay empty, got %q", doc.Data["EMPTY"])
+	}
+	if doc.Sops.EncryptedRegex != DefaultEncryptedRegex {
+		t.Errorf("encrypted_regex = %q", doc.Sops.EncryptedRegex)
+	}
+	if len(doc.Sops.Age) != 1 || doc.Sops.Age[0].Recipient != id.Recipient().String() {
+		t.Errorf("age stanzas = %+v", doc.Sops.Age)
+	}
+	if !strings.Contains(doc.Sops.Age[0].Enc, "BEGIN AGE ENCRYPTED FILE") {
+		t.Errorf("data key should be armored: %q", doc.Sops.Age[0].Enc)
+	}
+	if !strings.HasPrefix(doc.Sops.MAC, "ENC[AES256_GCM,") || doc.Sops.LastModified == "" {
+		t.Errorf("mac/lastmodified missing: %+v", doc.Sops)
+	}
+	if !IsEncrypted(enc) {
+		t.Error("IsEncrypted = false for encrypted output")
+	}
+	if IsEncrypted([]byte(plainSecret)) {
+		t.Error("IsEncrypted = true for plain input")
+	}
+}
+
+func TestDecrypt_RoundTrip(t *testing.T) {
+	id := newIdentity(t)
+	enc := encryptFor(t, plainSecret, id)
+
+	plain, k, err := Decrypt(enc, []age.Identity{id})
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if string(plain) != plainSecret {
+		t.Errorf("round trip mismatch:\ngot:\n%s\nwant:\n%s", plain, plainSecret)
+	}
+	if got := k.Recipients(); len(got) != 1 || got[0] != id.Recipient().String() {
+		t.Errorf("Recipients = %v", got)
+	}
+
+	// Re-encrypting with the recovered key must be readable by the same identity.
+	again, err := k.Encrypt(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Decrypt(again, []age.Identity{id}); err != nil {
+		t.Errorf("decrypt after re-encrypt: %v", err)
+	}
+}
+
+func TestDecrypt_AnyRecipient(t *testing.T) {
+	alice, bob := newIdentity(t), newIdentity(t)
+	enc := encryptFor(t, plainSecret, alice, bob)
+	if _, _, err := Decrypt(enc, []age.Identity{bob}); err != nil {
+		t.Errorf("second recipient should decrypt: %v", err)
+	}
+}
+
+func TestDecrypt_WrongIdentity(t *testing.T) {
+	enc := encryptFor(t, plainSecret, newIdentity(t))
+	if _, _, err := Decrypt(enc, []age.Identity{newIdentity(t)}); err == nil {
+		t.Error("expected error for wrong identity")
+	}
+}
+
+func TestDecrypt_DetectsTampering(t *testing.T) {
+	id := newIdentity(t)
+	enc := encryptFor(t, plainSecret, id)
+
+	// Editing a plain-text field outside sops invalidates the MAC.
+	tampered := strings.Replace(string(enc), "name: app", "name: evil", 1)
+	if _, _, err := Decrypt([]byte(tampered), []age.Identity{id}); err == nil || !strings.Contains(err.Error(), "MAC") {
+		t.Errorf("expected MAC mismatch, got %v", err)
+	}
+}
+
+func TestDecrypt_ValueBoundToPath(t *testing.T) {
+	id := newIdentity(t)
+	enc := encryptFor(t, "data:\n  A: Zmlyc3Q=\n  B: c2Vjb25k\n", id)
+
+	var tree yaml.MapSlice
+	if err := yaml.Unmarshal(enc, &tree); err != nil {
+		t.Fatal(err)
+	}
+	data := tree[0].Value.(yaml.MapSlice)
+	data[0].Value, data[1].Value = data[1].Value, data[0].Value
+	swapped, err := yaml.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Decrypt(swapped, []age.Identity{id}); err == nil {
+		t.Error("expected error when encrypted values are moved between keys")
+	}
+}
+
+func TestNewKey_Errors(t *testing.T) {
+	if _, err := NewKey(nil, ""); err == nil {
+		t.Error("expected error for no recipients")
+	}
+	if _, err := NewKey([]string{"not-a-key"}, ""); err == nil {
+		t.Error("expected error for invalid recipient")
+	}
+	if _, err := NewKey([]string{newIdentity(t).Recipient().String()}, "("); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
+
+func TestEncrypt_RejectsEncrypted(t *testing.T) {
+	id := newIdentity(t)
+	enc := encryptFor(t, plainSecret, id)
+	k, err := NewKey([]string{id.Recipient().String()}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Encrypt(enc); err == nil {
+		t.Error("expected error encrypting an already encrypted document")
+	}
+}
@@ -0,0 +1,153 @@
+package validate
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/pemutil"
+)
+
+// Issue codes for the content checks of typed Secrets.
+const (
+	CodeTLSCertInvalid      = "tls-cert-invalid"
+	CodeTLSKeyInvalid       = "tls-key-invalid"
+	CodeTLSKeyMismatch      = "tls-key-mismatch"
+	CodeTLSCertExpired      = "tls-cert-expired"
+	CodeTLSCertExpiring     = "tls-cert-expiring"
+	CodeDockerConfigInvalid = "dockerconfig-invalid"
+	CodeDockerAuthInvalid   = "dockerconfig-auth-invalid"
+	CodeSSHKeyInvalid       = "ssh-key-invalid"
+	CodeSSHKeyEncrypted     = "ssh-key-encrypted"
+)
+
+// checkContent parses the payload of typed Secrets. Missing keys are
+// reported by checkTypeRequirements and skipped here.
+func checkContent(s *corev1.Secret, opts Options) []Issue {
+	switch s.Type {
+	case corev1.SecretTypeTLS:
+		return checkTLS(s, opts)
+	case corev1.SecretTypeDockerConfigJson:
+		return checkDockerConfigJSON(s)
+	case corev1.SecretTypeSSHAuth:
+		return checkSSHAuth(s)
+	}
+	return nil
+}
+
+func checkTLS(s *corev1.Secret, opts Options) []Issue {
+	var issues []Issue
+
+	var leaf *x509.Certificate
+	if crtPEM, ok := s.Data[corev1.TLSCertKey]; ok {
+		certs, err := pemutil.ParseCertificates(crtPEM)
+		if err != nil {
+			issues = append(issues, Issue{SeverityError, CodeTLSCertInvalid, corev1.TLSCertKey,
+				fmt.Sprintf("%s: %v", corev1.TLSCertKey, err)})
+		} else {
+			leaf = certs[0]
+		}
+	}
+
+	var key crypto.Signer
+	if keyPEM, ok := s.Data[corev1.TLSPrivateKeyKey]; ok {
+		var err error
+		if key, err = pemutil.ParsePrivateKey(keyPEM); err != nil {
+			issues = append(issues, Issue{SeverityError, CodeTLSKeyInvalid, corev1.TLSPrivateKeyKey,
+				fmt.Sprintf("%s: %v", corev1.TLSPrivateKeyKey, err)})
+		}
+	}
+
+	if leaf != nil && key != nil {
+		pub, ok := leaf.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+		if !ok || !pub.Equal(key.Public()) {
+			issues = append(issues, Issue{SeverityError, CodeTLSKeyMismatch, corev1.TLSPrivateKeyKey,
+				fmt.Sprintf("%s does not match the certificate in %s", corev1.TLSPrivateKeyKey, corev1.TLSCertKey)})
+		}
+	}
+
+	if leaf != nil {
+		now := opts.now()
+		switch {
+		case now.After(leaf.NotAfter):
+			issues = append(issues, Issue{SeverityWarning, CodeTLSCertExpired, corev1.TLSCertKey,
+				fmt.Sprintf("certificate %q expired on %s", leaf.Subject.CommonName, leaf.NotAfter.UTC().Format(time.RFC3339))})
+		case opts.CertExpiryWindow > 0 && now.Add(opts.CertExpiryWindow).After(leaf.NotAfter):
+			issues = append(issues, Issue{SeverityWarning, CodeTLSCertExpiring, corev1.TLSCertKey,
+				fmt.Sprintf("certificate %q expires on %s (in %d day(s))", leaf.Subject.CommonName,
+					leaf.NotAfter.UTC().Format(time.RFC3339), int(leaf.NotAfter.Sub(now).Hours()/24))})
+		}
+	}
+
+	return issues
+}
+
+func checkDockerConfigJSON(s *corev1.Secret) []Issue {
+	data, ok := s.Data[corev1.DockerConfigJsonKey]
+	if !ok {
+		return nil
+	}
+	invalid := func(format string, args ...any) []Issue {
+		return []Issue{{SeverityError, CodeDockerConfigInvalid, corev1.DockerConfigJsonKey,
+			corev1.DockerConfigJsonKey + ": " + fmt.Sprintf(format, args...)}}
+	}
+
+	var cfg struct {
+		Auths map[string]struct {
+			Auth *string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return invalid("not valid JSON: %v", err)
+	}
+	if cfg.Auths == nil {
+		return invalid(`missing "auths" map`)
+	}
+
+	var issues []Issue
+	registries := make([]string, 0, len(cfg.Auths))
+	for r := range cfg.Auths {
+		registries = append(registries, r)
+	}
+	slices.Sort(registries)
+	for _, r := range registries {
+		auth := cfg.Auths[r].Auth
+		if auth == nil {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(*auth)
+		if err != nil || !strings.Contains(string(decoded), ":") {
+			issues = append(issues, Issue{SeverityError, CodeDockerAuthInvalid, corev1.DockerConfigJsonKey,
+				fmt.Sprintf("%s: auth for %q is not base64 of username:password", corev1.DockerConfigJsonKey, r)})
+		}
+	}
+	return issues
+}
+
+func checkSSHAuth(s *corev1.Secret) []Issue {
+	data, ok := s.Data[corev1.SSHAuthPrivateKey]
+	if !ok {
+		return nil
+	}
+	_, err := ssh.ParseRawPrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &missing):
+		return []Issue{{SeverityWarning, CodeSSHKeyEncrypted, corev1.SSHAuthPrivateKey,
+			fmt.Sprintf("%s is passphrase-protected and cannot be used non-interactively", corev1.SSHAuthPrivateKey)}}
+	default:
+		return []Issue{{SeverityError, CodeSSHKeyInvalid, corev1.SSHAuthPrivateKey,
+			fmt.Sprintf("%s: %v", corev1.SSHAuthPrivateKey, err)}}
+	}
+}
@@ -0,0 +1,177 @@
+package validate_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/validate"
+)
+
+// tlsPair returns a self-signed certificate and its PKCS#8 key, both PEM.
+func tlsPair(t *testing.T, notAfter time.Time) (crt, key []byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    notAfter.Add(-2 * 365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+// sshKey returns an unencrypted OpenSSH Ed25519 private key.
+func sshKey(t *testing.T) []byte {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+func tlsSecret(crt, key []byte) *corev1.Secret {
+	s := makeSecret("valid", "default")
+	s.Type = corev1.SecretTypeTLS
+	s.Data = map[string][]byte{"tls.crt": crt, "tls.key": key}
+	return s
+}
+
+func hasCode(issues []validate.Issue, code string) bool {
+	for _, i := range issues {
+		if i.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// ---- TLS content ----
+
+func TestTLS_InvalidPEM(t *testing.T) {
+	issues := validate.Secret(tlsSecret([]byte("cert"), []byte("key")))
+	if !hasCode(issues, validate.CodeTLSCertInvalid) || !hasCode(issues, validate.CodeTLSKeyInvalid) {
+		t.Errorf("expected invalid cert and key, got: %v", issues)
+	}
+}
+
+func TestTLS_KeyMismatch(t *testing.T) {
+	future := time.Now().Add(365 * 24 * time.Hour)
+	crt, _ := tlsPair(t, future)
+	_, otherKey := tlsPair(t, future)
+	if !hasCode(validate.Secret(tlsSecret(crt, otherKey)), validate.CodeTLSKeyMismatch) {
+		t.Error("expected key mismatch error")
+	}
+}
+
+func TestTLS_Expired(t *testing.T) {
+	crt, key := tlsPair(t, time.Now().Add(-time.Hour))
+	issues := validate.Secret(tlsSecret(crt, key))
+	if !hasCode(issues, validate.CodeTLSCertExpired) || hasAnyError(issues) {
+		t.Errorf("expected expired warning only, got: %v", issues)
+	}
+}
+
+func TestTLS_ExpiryWindow(t *testing.T) {
+	crt, key := tlsPair(t, time.Now().Add(10*24*time.Hour))
+	s := tlsSecret(crt, key)
+
+	if !hasCode(validate.Secret(s), validate.CodeTLSCertExpiring) {
+		t.Error("expected expiring warning inside the default 30-day window")
+	}
+	opts := validate.Options{CertExpiryWindow: 7 * 24 * time.Hour}
+	if hasCode(validate.SecretWithOptions(s, opts), validate.CodeTLSCertExpiring) {
+		t.Error("no warning expected outside a 7-day window")
+	}
+	opts.Now = time.Now().Add(5 * 24 * time.Hour)
+	if !hasCode(validate.SecretWithOptions(s, opts), validate.CodeTLSCertExpiring) {
+		t.Error("expected warning relative to Options.Now")
+	}
+}
+
+// ---- dockerconfigjson content ----
+
+func TestDockerConfigJson_Content(t *testing.T) {
+	cases := []struct {
+		name, json, code string
+	}{
+		{"not json", `{`, validate.CodeDockerConfigInvalid},
+		{"no auths", `{}`, validate.CodeDockerConfigInvalid},
+		{"auth not base64", `{"auths":{"r":{"auth":"***"}}}`, validate.CodeDockerAuthInvalid},
+		{"auth without colon", `{"auths":{"r":{"auth":"dXNlcg=="}}}`, validate.CodeDockerAuthInvalid},
+		{"username only", `{"auths":{"r":{"username":"u","password":"p"}}}`, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := makeSecret("valid", "default")
+			s.Type = corev1.SecretTypeDockerConfigJson
+			s.Data = map[string][]byte{corev1.DockerConfigJsonKey: []byte(tc.json)}
+			issues := validate.Secret(s)
+			if tc.code == "" {
+				if len(issues) > 0 {
+					t.Errorf("expected no issues, got: %v", issues)
+				}
+				return
+			}
+			if !hasCode(issues, tc.code) {
+				t.Errorf("expected %s, got: %v", tc.code, issues)
+			}
+		})
+	}
+}
+
+// ---- ssh-auth content ----
+
+func TestSSHAuth_InvalidKey(t *testing.T) {
+	s := makeSecret("valid", "default")
+	s.Type = corev1.SecretTypeSSHAuth
+	s.Data = map[string][]byte{"ssh-privatekey": []byte("not a key")}
+	if !hasCode(validate.Secret(s), validate.CodeSSHKeyInvalid) {
+		t.Error("expected invalid ssh key error")
+	}
+}
+
+func TestSSHAuth_EncryptedKeyWarns(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := makeSecret("valid", "default")
+	s.Type = corev1.SecretTypeSSHAuth
+	s.Data = map[string][]byte{"ssh-privatekey": pem.EncodeToMemory(block)}
+	issues := validate.Secret(s)
+	if !hasCode(issues, validate.CodeSSHKeyEncrypted) || hasAnyError(issues) {
+		t.Errorf("expected encrypted-key warning only, got: %v", issues)
+	}
+}
@@ -0,0 +1,98 @@
+package validate
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// API server limits enforced by Limits.
+const (
+	// MaxSecretSize is the maximum total size of a Secret's data, counted as
+	// the sum of the value lengths; key names do not count, as in the API
+	// server's ValidateSecret.
+	MaxSecretSize = 1024 * 1024
+
+	// MaxAnnotationsSize is the maximum total size of all annotation keys and
+	// values on an object.
+	MaxAnnotationsSize = 256 * 1024
+)
+
+// Issue codes for API server limits.
+const (
+	CodeSecretTooLarge       = "secret-too-large"
+	CodeLabelKeyInvalid      = "label-key-invalid"
+	CodeLabelValueInvalid    = "label-value-invalid"
+	CodeAnnotationKeyInvalid = "annotation-key-invalid"
+	CodeAnnotationsTooLarge  = "annotations-too-large"
+)
+
+// Limits checks the rules the API server enforces on create and update that
+// the other checks do not cover: the 1 MiB Secret size limit, label keys and
+// values, and annotation keys and total size. Every finding is an error.
+// Mutating commands run it before writing a manifest.
+func Limits(s *corev1.Secret) []Issue {
+	var issues []Issue
+	issues = append(issues, checkSize(s)...)
+	issues = append(issues, checkLabels(s)...)
+	issues = append(issues, checkAnnotations(s)...)
+	return issues
+}
+
+func checkSize(s *corev1.Secret) []Issue {
+	total := 0
+	for _, v := range s.Data {
+		total += len(v)
+	}
+	if total > MaxSecretSize {
+		return []Issue{{SeverityError, CodeSecretTooLarge, "", fmt.Sprintf(
+			"secret data is %d bytes, exceeding the %d byte (1 MiB) limit", total, MaxSecretSize,
+		)}}
+	}
+	return nil
+}
+
+func checkLabels(s *corev1.Secret) []Issue {
+	var issues []Issue
+	for _, k := range sortedKeys(s.Labels) {
+		if errs := k8svalidation.IsQualifiedName(k); len(errs) > 0 {
+			issues = append(issues, Issue{SeverityError, CodeLabelKeyInvalid, "", fmt.Sprintf(
+				"label key %q is invalid: %s", k, strings.Join(errs, "; "),
+			)})
+		}
+		if errs := k8svalidation.IsValidLabelValue(s.Labels[k]); len(errs) > 0 {
+			issues = append(issues, Issue{SeverityError, CodeLabelValueInvalid, "", fmt.Sprintf(
+				"label %q value %q is invalid: %s", k, s.Labels[k], strings.Join(errs, "; "),
+			)})
+		}
+	}
+	return issues
+}
+
+func checkAnnotations(s *corev1.Secret) []Issue {
+	var issues []Issue
+	total := 0
+	for _, k := range sortedKeys(s.Annotations) {
+		total += len(k) + len(s.Annotations[k])
+		// Annotation keys follow the same qualified-name rules as label keys.
+		if errs := k8svalidation.IsQualifiedName(strings.ToLower(k)); len(errs) > 0 {
+			issues = append(issues, Issue{SeverityError, CodeAnnotationKeyInvalid, "", fmt.Sprintf(
+				"annotation key %q is invalid: %s", k, strings.Join(errs, "; "),
+			)})
+		}
+	}
+	if total > MaxAnnotationsSize {
+		issues = append(issues, Issue{SeverityError, CodeAnnotationsTooLarge, "", fmt.Sprintf(
+			"annotations total %d bytes, exceeding the %d byte (256 KiB) limit", total, MaxAnnotationsSize,
+		)})
+	}
+	return issues
+}
+
+func sortedKeys(m map[string]string) []string {
+	return slices.Sorted(maps.Keys(m))
+}
@@ -0,0 +1,78 @@
+package validate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pbsladek/k8s-secret-manifest/internal/validate"
+)
+
+func TestLimits_Valid(t *testing.T) {
+	s := makeSecret("valid", "default")
+	s.Labels = map[string]string{"app.kubernetes.io/name": "api", "env": ""}
+	s.Annotations = map[string]string{"example.com/Owner": "team a, with spaces"}
+	if issues := validate.Limits(s); len(issues) > 0 {
+		t.Errorf("expected no issues, got: %v", issues)
+	}
+}
+
+func TestLimits_SecretTooLarge(t *testing.T) {
+	s := makeSecret("valid", "default")
+	s.Data = map[string][]byte{"big": make([]byte, validate.MaxSecretSize+1)}
+	issues := validate.Limits(s)
+	if !hasCode(issues, validate.CodeSecretTooLarge) || !hasAnyError(issues) {
+		t.Errorf("expected size error, got: %v", issues)
+	}
+
+	// Values totalling exactly the limit are allowed; key names do not count.
+	half := validate.MaxSecretSize / 2
+	s.Data = map[string][]byte{
+		"first_half_of_the_data":  make([]byte, half),
+		"second_half_of_the_data": make([]byte, validate.MaxSecretSize-half),
+		"empty":                   nil,
+	}
+	if issues := validate.Limits(s); len(issues) > 0 {
+		t.Errorf("expected no issues at the limit, got: %v", issues)
+	}
+}
+
+func TestLimits_Labels(t *testing.T) {
+	cases := []struct {
+		key, value, code string
+	}{
+		{"has space", "v", validate.CodeLabelKeyInvalid},
+		{"-leading", "v", validate.CodeLabelKeyInvalid},
+		{"Bad_Prefix/name", "v", validate.CodeLabelKeyInvalid},
+		{strings.Repeat("a", 64), "v", validate.CodeLabelKeyInvalid},
+		{"ok", "has space", validate.CodeLabelValueInvalid},
+		{"ok", strings.Repeat("a", 64), validate.CodeLabelValueInvalid},
+	}
+	for _, tc := range cases {
+		s := makeSecret("valid", "default")
+		s.Labels = map[string]string{tc.key: tc.value}
+		if !hasCode(validate.Limits(s), tc.code) {
+			t.Errorf("label %q=%q: expected %s", tc.key, tc.value, tc.code)
+		}
+	}
+}
+
+func TestLimits_Annotations(t *testing.T) {
+	s := makeSecret("valid", "default")
+	s.Annotations = map[string]string{"not valid": "v"}
+	if !hasCode(validate.Limits(s), validate.CodeAnnotationKeyInvalid) {
+		t.Error("expected invalid annotation key")
+	}
+
+	s.Annotations = map[string]string{"big": strings.Repeat("a", validate.MaxAnnotationsSize)}
+	if !hasCode(validate.Limits(s), validate.CodeAnnotationsTooLarge) {
+		t.Error("expected annotations size error")
+	}
+}
+
+func TestSecret_IncludesLimits(t *testing.T) {
+	s := makeSecret("valid", "default")
+	s.Labels = map[string]string{"bad key": "v"}
+	if !hasCode(validate.Secret(s), validate.CodeLabelKeyInvalid) {
+		t.Error("validate.Secret should report limit violations")
+	}
+}
@@ -3,7 +3,10 @@ package validate
 
 import (
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -14,10 +17,29 @@ const (
 	SeverityWarning = "warning"
 )
 
+// Issue codes identify the check that produced an Issue. They are part of the
+// machine-readable output (validate --output-format json) and do not change
+// between releases; Message wording may.
+const (
+	CodeNameEmpty             = "name-empty"
+	CodeNameTooLong           = "name-too-long"
+	CodeNameInvalid           = "name-invalid"
+	CodeNamespaceEmpty        = "namespace-empty"
+	CodeNamespaceTooLong      = "namespace-too-long"
+	CodeNamespaceInvalid      = "namespace-invalid"
+	CodeNoData                = "no-data"
+	CodeDataKeyInvalid        = "data-key-invalid"
+	CodeRequiredKeyMissing    = "required-key-missing"
+	CodeRecommendedKeyMissing = "recommended-key-missing"
+)
+
 // Issue represents a single validation finding.
+// Key is the data key the finding concerns, if any.
 type Issue struct {
-	Severity string
-	Message  string
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Key      string `json:"key,omitempty"`
+	Message  string `json:"message"`
 }
 
 func (i Issue) IsError() bool { return i.Severity == SeverityError }
@@ -35,28 +57,56 @@ var (
 	dataKeyRe = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
 )
 
-// Secret validates a corev1.Secret and returns all findings.
-// Errors indicate spec violations; warnings indicate likely mistakes.
+// DefaultCertExpiryWindow is how long before expiry a TLS certificate is
+// reported as expiring.
+const DefaultCertExpiryWindow = 30 * 24 * time.Hour
+
+// Options tunes the content checks of SecretWithOptions.
+type Options struct {
+	// CertExpiryWindow warns about TLS certificates expiring within this
+	// window. Zero only reports certificates that have already expired.
+	CertExpiryWindow time.Duration
+
+	// Now is the reference time for expiry checks; zero means time.Now().
+	Now time.Time
+}
+
+func (o Options) now() time.Time {
+	if o.Now.IsZero() {
+		return time.Now()
+	}
+	return o.Now
+}
+
+// Secret validates a corev1.Secret with the default Options and returns all
+// findings. Errors indicate spec violations; warnings indicate likely mistakes.
 func Secret(s *corev1.Secret) []Issue {
+	return SecretWithOptions(s, Options{CertExpiryWindow: DefaultCertExpiryWindow})
+}
+
+// SecretWithOptions is Secret with explicit Options.
+func SecretWithOptions(s *corev1.Secret, opts Options) []Issue {
 	var issues []Issue
 
 	issues = append(issues, checkName(s)...)
 	issues = append(issues, checkNamespace(s)...)
 	issues = append(issues, checkDataKeys(s)...)
+	issues = append(issues, Limits(s)...)
 	issues = append(issues, checkTypeRequirements(s)...)
+	issues = append(issues, checkContent(s, opts)...)
 
 	return issues
 }
 
 func checkName(s *corev1.Secret) []Issue {
 	if s.Name == "" {
-		return []Issue{{SeverityError, "name must not be empty"}}
+		return []Issue{{SeverityError, CodeNameEmpty, "", "name must not be empty"}}
 	}
 	if len(s.Name) > 253 {
-		return []Issue{{SeverityError, fmt.Sprintf("name %q exceeds 253 characters", s.Name)}}
+		return []Issue{{SeverityError, CodeNameTooLong, "", fmt.Sprintf("name %q exceeds 253 characters", s.Name)}}
 	}
 	if !nameRe.MatchString(s.Name) {
-		return []Issue{{SeverityError, fmt.Sprintf(
+		return []Issue{{SeverityError, CodeNameInvalid, "", fmt.Sprintf(
 			"name %q is not a valid DNS subdomain (lowercase alphanumeric, hyphens, dots; must start and end with alphanumeric)",
 			s.Name,
 		)}}
@@ -66,13 +116,13 @@ func checkName(s *corev1.Secret) []Issue {
 
 func checkNamespace(s *corev1.Secret) []Issue {
 	if s.Namespace == "" {
-		return []Issue{{SeverityError, "namespace must not be empty"}}
+		return []Issue{{SeverityError, CodeNamespaceEmpty, "", "namespace must not be empty"}}
 	}
 	if len(s.Namespace) > 63 {
-		return []Issue{{SeverityError, fmt.Sprintf("namespace %q exceeds 63 characters", s.Namespace)}}
+		return []Issue{{SeverityError, CodeNamespaceTooLong, "", fmt.Sprintf("namespace %q exceeds 63 characters", s.Namespace)}}
 	}
 	if !namespaceRe.MatchString(s.Namespace) {
-		return []Issue{{SeverityError, fmt.Sprintf(
+		return []Issue{{SeverityError, CodeNamespaceInvalid, "", fmt.Sprintf(
 			"namespace %q is not a valid DNS label (lowercase alphanumeric and hyphens; must start and end with alphanumeric)",
 			s.Namespace,
 		)}}
@@ -92,11 +142,11 @@ func ValidateDataKey(key string) error {
 func checkDataKeys(s *corev1.Secret) []Issue {
 	var issues []Issue
 	if len(s.Data) == 0 {
-		issues = append(issues, Issue{SeverityWarning, "secret has no data keys"})
+		issues = append(issues, Issue{SeverityWarning, CodeNoData, "", "secret has no data keys"})
 	}
-	for k := range s.Data {
+	for _, k := range slices.Sorted(maps.Keys(s.Data)) {
 		if !dataKeyRe.MatchString(k) {
-			issues = append(issues, Issue{SeverityError, fmt.Sprintf(
+			issues = append(issues, Issue{SeverityError, CodeDataKeyInvalid, k, fmt.Sprintf(
 				"data key %q contains invalid characters (allowed: alphanumeric, '-', '_', '.')",
 				k,
 			)})
@@ -110,14 +160,14 @@ func checkTypeRequirements(s *corev1.Secret) []Issue {
 
 	required := func(key string) {
 		if _, ok := s.Data[key]; !ok {
-			issues = append(issues, Issue{SeverityError, fmt.Sprintf(
+			issues = append(issues, Issue{SeverityError, CodeRequiredKeyMissing, key, fmt.Sprintf(
 				"type %s requires data key %q", s.Type, key,
 			)})
 		}
 	}
 	recommended := func(key string) {
 		if _, ok := s.Data[key]; !ok {
-			issues = append(issues, Issue{SeverityWarning, fmt.Sprintf(
+			issues = append(issues, Issue{SeverityWarning, CodeRecommendedKeyMissing, key, fmt.Sprintf(
 				"type %s typically requires data key %q", s.Type, key,
 			)})
 		}
@@ -3,6 +3,7 @@ package validate_test
 import (
 	"strings"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -159,9 +160,10 @@ func TestTLS_MissingKey(t *testing.T) {
 func TestTLS_Valid(t *testing.T) {
 	s := makeSecret("valid", "default")
 	s.Type = corev1.SecretTypeTLS
-	s.Data = map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}
-	if hasAnyError(validate.Secret(s)) {
-		t.Error("valid TLS secret should have no errors")
+	crt, key := tlsPair(t, time.Now().Add(365*24*time.Hour))
+	s.Data = map[string][]byte{"tls.crt": crt, "tls.key": key}
+	if issues := validate.Secret(s); len(issues) > 0 {
+		t.Errorf("valid TLS secret should have no issues, got: %v", issues)
 	}
 }
 
@@ -179,7 +181,7 @@ func TestDockerConfigJson_Missing(t *testing.T) {
 func TestDockerConfigJson_Valid(t *testing.T) {
 	s := makeSecret("valid", "default")
 	s.Type = corev1.SecretTypeDockerConfigJson
-	s.Data = map[string][]byte{corev1.DockerConfigJsonKey: []byte("{}")}
+	s.Data = map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"ghcr.io":{"auth":"dXNlcjpwYXNz"}}}`)}
 	if hasAnyError(validate.Secret(s)) {
 		t.Error("valid docker-registry secret should have no errors")
 	}
@@ -220,7 +222,7 @@ func TestSSHAuth_Missing(t *testing.T) {
 func TestSSHAuth_Valid(t *testing.T) {
 	s := makeSecret("valid", "default")
 	s.Type = corev1.SecretTypeSSHAuth
-	s.Data = map[string][]byte{"ssh-privatekey": []byte("key")}
+	s.Data = map[string][]byte{"ssh-privatekey": sshKey(t)}
 	if hasAnyError(validate.Secret(s)) {
 		t.Error("valid ssh-auth secret should have no errors")
 	}
@@ -266,6 +268,29 @@ func TestIssue_String(t *testing.T) {
 	}
 }
 
+func TestIssue_CodeAndKey(t *testing.T) {
+	s := makeSecret("valid", "default")
+	s.Type = corev1.SecretTypeSSHAuth
+	s.Data = map[string][]byte{"bad key": []byte("v")}
+
+	var codes []string
+	for _, i := range validate.Secret(s) {
+		codes = append(codes, i.Code+"/"+i.Key)
+	}
+	got := strings.Join(codes, ",")
+	want := validate.CodeDataKeyInvalid + "/bad key," + validate.CodeRequiredKeyMissing + "/ssh-privatekey"
+	if got != want {
+		t.Errorf("codes = %q, want %q", got, want)
+	}
+}
+
+func TestIssue_CodeForName(t *testing.T) {
+	issues := validate.Secret(makeSecret("", "default"))
+	if len(issues) == 0 || issues[0].Code != validate.CodeNameEmpty || issues[0].Key != "" {
+		t.Errorf("unexpected issues: %+v", issues)
+	}
+}
+
 // ---- helpers ----
 
 func hasError(issues []validate.Issue, msg string) bool {